@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// archiveManifest records where archived items came from. It lives in the
+// archive dir, which is hidden from the sidebar.
+const archiveManifest = ".manifest.yaml"
+
+// archiveNameRe splits an archived name into its timestamp prefix and the
+// original base name.
+var archiveNameRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}-\d{6})-(.+)$`)
+
+// ArchiveEntry is an item in the archive dir.
+type ArchiveEntry struct {
+	Name       string    `yaml:"name"`     // file name in the archive dir
+	Original   string    `yaml:"original"` // path in the notes dir before archiving
+	ArchivedAt time.Time `yaml:"archived_at"`
+	IsDir      bool      `yaml:"-"`
+	Size       int64     `yaml:"-"` // bytes, including the contents of folders
+}
+
+func loadManifest(cfg *Config) (map[string]ArchiveEntry, error) {
+	entries := make(map[string]ArchiveEntry)
+	data, err := os.ReadFile(filepath.Join(cfg.ArchiveDir, archiveManifest))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []ArchiveEntry
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid archive manifest: %v", err)
+	}
+	for _, entry := range list {
+		entries[entry.Name] = entry
+	}
+	return entries, nil
+}
+
+// updateManifest loads the manifest, applies change and writes it back.
+func updateManifest(cfg *Config, change func(entries map[string]ArchiveEntry)) error {
+	entries, err := loadManifest(cfg)
+	if err != nil {
+		return err
+	}
+	change(entries)
+
+	list := make([]ArchiveEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(cfg.ArchiveDir, archiveManifest), data)
+}
+
+// archiveNote moves a note or folder into the archive dir under a timestamped
+// name, records its original path and returns its new path.
+func archiveNote(cfg *Config, path string) (string, error) {
+	if err := os.MkdirAll(cfg.ArchiveDir, 0755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s", now.Format("2006-01-02-150405"), filepath.Base(path))
+	for counter := 2; ; counter++ {
+		if _, err := os.Lstat(filepath.Join(cfg.ArchiveDir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d-%s", now.Format("2006-01-02-150405"), counter, filepath.Base(path))
+	}
+	archivePath := filepath.Join(cfg.ArchiveDir, name)
+	if err := os.Rename(path, archivePath); err != nil {
+		return "", err
+	}
+
+	original := filepath.Clean(path)
+	if rel, err := filepath.Rel(cfg.NotesDir, path); err == nil && filepath.IsAbs(path) {
+		original = rel
+	}
+	err := updateManifest(cfg, func(entries map[string]ArchiveEntry) {
+		entries[name] = ArchiveEntry{Name: name, Original: original, ArchivedAt: now}
+	})
+	return archivePath, err
+}
+
+// ListArchive returns the archived items, newest first. Items archived before
+// the manifest existed get their original name and time from the timestamp
+// prefix and are assumed to come from the top of the notes dir. The zips
+// packed by the retention policy are not items that can be restored, so
+// they are left out.
+func ListArchive(cfg *Config) ([]ArchiveEntry, error) {
+	all, err := listArchiveDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ArchiveEntry
+	for _, entry := range all {
+		if !isArchiveZip(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// listArchiveDir lists everything in the archive dir, retention zips
+// included, newest first.
+func listArchiveDir(cfg *Config) ([]ArchiveEntry, error) {
+	manifest, err := loadManifest(cfg)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(cfg.ArchiveDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var entries []ArchiveEntry
+	for _, f := range files {
+		if f.Name() == archiveManifest {
+			continue
+		}
+		entry, ok := manifest[f.Name()]
+		if !ok {
+			entry = ArchiveEntry{Name: f.Name(), Original: f.Name()}
+			if match := archiveNameRe.FindStringSubmatch(f.Name()); match != nil {
+				entry.Original = match[2]
+				entry.ArchivedAt, _ = time.ParseInLocation("2006-01-02-150405", match[1], time.Local)
+			} else if info, err := f.Info(); err == nil {
+				entry.ArchivedAt = info.ModTime()
+			}
+		}
+		entry.IsDir = f.IsDir()
+		entry.Size = diskUsage(filepath.Join(cfg.ArchiveDir, f.Name()))
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].ArchivedAt.Equal(entries[j].ArchivedAt) {
+			return entries[i].ArchivedAt.After(entries[j].ArchivedAt)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// diskUsage adds up the size of the files below path.
+func diskUsage(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// restoreArchived moves an archived item back to dest in the notes dir and
+// forgets it in the manifest.
+func restoreArchived(cfg *Config, entry ArchiveEntry, dest string) error {
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(cfg.ArchiveDir, entry.Name), dest); err != nil {
+		return err
+	}
+	return updateManifest(cfg, func(entries map[string]ArchiveEntry) {
+		delete(entries, entry.Name)
+	})
+}
+
+// purgeArchived permanently deletes an archived item.
+func purgeArchived(cfg *Config, entry ArchiveEntry) error {
+	if err := os.RemoveAll(filepath.Join(cfg.ArchiveDir, entry.Name)); err != nil {
+		return err
+	}
+	return updateManifest(cfg, func(entries map[string]ArchiveEntry) {
+		delete(entries, entry.Name)
+	})
+}
+
+func (m *Model) loadArchive() {
+	var err error
+	if m.archiveEntries, err = ListArchive(m.config); err != nil {
+		m.notifyError("Could not read the archive: %v", err)
+	}
+	if m.archiveCursor >= len(m.archiveEntries) {
+		m.archiveCursor = len(m.archiveEntries) - 1
+	}
+	if m.archiveCursor < 0 {
+		m.archiveCursor = 0
+	}
+	m.previewArchived()
+}
+
+// previewArchived shows the selected archived note, or the contents of an
+// archived folder, in the preview pane.
+func (m *Model) previewArchived() {
+	if len(m.archiveEntries) == 0 {
+		m.viewport.SetContent("")
+		return
+	}
+	entry := m.archiveEntries[m.archiveCursor]
+	path := filepath.Join(m.config.ArchiveDir, entry.Name)
+	if entry.IsDir {
+		var files []string
+		filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				rel, _ := filepath.Rel(path, p)
+				files = append(files, "- "+rel)
+			}
+			return nil
+		})
+		m.viewport.SetContent(m.renderMarkdown("# " + entry.Original + "\n\n" + strings.Join(files, "\n")))
+	} else if content, err := os.ReadFile(path); err == nil {
+		_, body := parseFrontmatter(string(content))
+		m.viewport.SetContent(m.renderMarkdown(body))
+	}
+	m.viewport.GotoTop()
+}
+
+func (m *Model) closeArchive() {
+	m.archiveView = false
+	m.confirmPurge = false
+	m.updatePreview()
+}
+
+// restoreSelected restores the selected archived item to dest and selects it
+// in the sidebar.
+func (m *Model) restoreSelected(dest string) {
+	entry := m.archiveEntries[m.archiveCursor]
+	if err := restoreArchived(m.config, entry, dest); err != nil {
+		m.notifyError("Could not restore %s: %v", entry.Original, err)
+		return
+	}
+	m.record(Operation{Kind: opRestore, Path: dest, Archive: entry.Name})
+	m.notify("Restored %s", dest)
+	m.index.Refresh(".", m.isArchiveDir)
+	m.updateNotes()
+	m.revealNote(dest)
+	m.closeArchive()
+}
+
+func (m Model) updateArchiveView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmPurge {
+		if msg.String() == "y" && len(m.archiveEntries) > 0 {
+			entry := m.archiveEntries[m.archiveCursor]
+			if err := purgeArchived(m.config, entry); err != nil {
+				m.notifyError("Could not delete %s: %v", entry.Original, err)
+			} else {
+				m.notify("Deleted %s permanently", entry.Original)
+			}
+			m.loadArchive()
+		}
+		m.confirmPurge = false
+		return m, nil
+	}
+
+	action := m.keys.in(panelArchive, msg.String())
+	if action == actionClear || action == actionArchiveView {
+		m.closeArchive()
+		return m, nil
+	}
+	if len(m.archiveEntries) == 0 {
+		return m, nil
+	}
+
+	entry := m.archiveEntries[m.archiveCursor]
+	switch action {
+	case actionUp:
+		if m.archiveCursor > 0 {
+			m.archiveCursor--
+			m.previewArchived()
+		}
+	case actionDown:
+		if m.archiveCursor < len(m.archiveEntries)-1 {
+			m.archiveCursor++
+			m.previewArchived()
+		}
+	case actionOpen, actionRestore:
+		m.restoreSelected(entry.Original)
+	case actionRestoreHere:
+		m.restoreSelected(filepath.Join(m.getCurrentDirectory(), filepath.Base(entry.Original)))
+	case actionPurge:
+		m.confirmPurge = true
+	}
+	return m, nil
+}
+
+func (m Model) formatArchive(width, height int) string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Archive"), ""}
+	if len(m.archiveEntries) == 0 {
+		lines = append(lines, m.styles.muted.Render("The archive is empty"))
+		return strings.Join(lines, "\n")
+	}
+
+	start := 0
+	if visible := (height - len(lines)) / 2; visible > 0 && m.archiveCursor >= visible {
+		start = m.archiveCursor - visible + 1
+	}
+	for i := start; i < len(m.archiveEntries) && len(lines)+2 <= height; i++ {
+		entry := m.archiveEntries[i]
+		style := lipgloss.NewStyle()
+		if i == m.archiveCursor {
+			style = style.Foreground(m.styles.highlight).Bold(true)
+		}
+		name := filepath.Base(entry.Original)
+		if entry.IsDir {
+			name += "/"
+		}
+		from := filepath.Dir(entry.Original)
+		if from == "." {
+			from = "notes"
+		}
+		lines = append(lines,
+			style.Render(ansi.Truncate(name, width, "…")),
+			m.styles.muted.Render(ansi.Truncate("  "+from+" • "+entry.ArchivedAt.Format("2006-01-02 15:04"), width, "…")),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
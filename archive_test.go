@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveRestore(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"projects/alpha.md": "# Alpha\n",
+		"inbox.md":          "# Inbox\n",
+	})
+	cfg := DefaultConfig()
+	cfg.NotesDir = root
+	cfg.ArchiveDir = filepath.Join(root, "archive")
+	os.MkdirAll(cfg.ArchiveDir, 0755)
+	os.WriteFile(filepath.Join(cfg.ArchiveDir, "2023-01-02-030405-old.md"), []byte("# Old\n"), 0644)
+
+	if _, err := archiveNote(cfg, filepath.Join("projects", "alpha.md")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archiveNote(cfg, "inbox.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListArchive(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ListArchive() = %+v, want 3 entries", entries)
+	}
+	byOriginal := make(map[string]ArchiveEntry)
+	for _, entry := range entries {
+		byOriginal[entry.Original] = entry
+	}
+	old, ok := byOriginal["old.md"]
+	if !ok || old.ArchivedAt.Year() != 2023 || entries[len(entries)-1].Name != old.Name {
+		t.Errorf("legacy entry = %+v, want it parsed from its name and listed last", old)
+	}
+
+	alpha := byOriginal[filepath.Join("projects", "alpha.md")]
+	os.RemoveAll("projects")
+	if err := restoreArchived(cfg, alpha, alpha.Original); err != nil {
+		t.Fatalf("restore to original: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("projects", "alpha.md")); err != nil {
+		t.Errorf("alpha was not restored: %v", err)
+	}
+
+	inbox := byOriginal["inbox.md"]
+	os.WriteFile("inbox.md", []byte("# New inbox\n"), 0644)
+	if err := restoreArchived(cfg, inbox, "inbox.md"); err == nil {
+		t.Errorf("restoring over an existing note should fail")
+	}
+	if err := restoreArchived(cfg, inbox, filepath.Join("projects", "inbox.md")); err != nil {
+		t.Errorf("restore to another folder: %v", err)
+	}
+
+	if err := purgeArchived(cfg, old); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := ListArchive(cfg); len(entries) != 0 {
+		t.Errorf("archive should be empty, got %+v", entries)
+	}
+	if manifest, _ := loadManifest(cfg); len(manifest) != 0 {
+		t.Errorf("manifest should be empty, got %+v", manifest)
+	}
+}
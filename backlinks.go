@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type Backlink struct {
+	Path, Title string
+	Context     string // the line containing the link
+	Offset      int    // byte offset of the link in the linking note
+}
+
+type span struct {
+	start, end int
+}
+
+// LinkedFrom lists, sorted, the other notes whose wikilinks resolve to path.
+func (idx *Index) LinkedFrom(path string) []string {
+	if idx.linkedFrom == nil {
+		idx.buildLinkedFrom()
+	}
+	return idx.linkedFrom[path]
+}
+
+func (idx *Index) buildLinkedFrom() {
+	idx.linkedFrom = make(map[string][]string)
+	for from, entry := range idx.Entries {
+		seen := make(map[string]bool)
+		for _, target := range entry.Links {
+			path, ok := idx.ResolveLink(target, from)
+			if !ok || path == from || seen[path] {
+				continue
+			}
+			seen[path] = true
+			idx.linkedFrom[path] = append(idx.linkedFrom[path], from)
+		}
+	}
+	for _, paths := range idx.linkedFrom {
+		sort.Strings(paths)
+	}
+}
+
+// Backlinks lists the notes whose wikilinks resolve to path, with the line
+// of the first such link. Only the linking notes are read.
+func (idx *Index) Backlinks(path string) []Backlink {
+	var backlinks []Backlink
+	for _, from := range idx.LinkedFrom(path) {
+		entry := idx.Entries[from]
+		content, err := os.ReadFile(from)
+		if err != nil {
+			continue
+		}
+		for _, link := range extractLinks(string(content)) {
+			if resolved, ok := idx.ResolveLink(link.Target, from); ok && resolved == path {
+				backlinks = append(backlinks, Backlink{
+					Path:    from,
+					Title:   entry.Title,
+					Context: lineAt(string(content), link.Start),
+					Offset:  link.Start,
+				})
+				break
+			}
+		}
+	}
+	return backlinks
+}
+
+func lineAt(content string, offset int) string {
+	start := strings.LastIndex(content[:offset], "\n") + 1
+	end := strings.Index(content[offset:], "\n")
+	if end == -1 {
+		end = len(content)
+	} else {
+		end += offset
+	}
+	return strings.TrimSpace(content[start:end])
+}
+
+// paragraphSpan returns the blank-line delimited block around offset, with
+// leading list, quote and heading markers left outside the span so the
+// markdown still renders as the same block.
+func paragraphSpan(content string, offset int) span {
+	start := strings.LastIndex(content[:offset], "\n\n")
+	if start == -1 {
+		start = 0
+	} else {
+		start += 2
+	}
+	end := strings.Index(content[offset:], "\n\n")
+	if end == -1 {
+		end = len(content)
+	} else {
+		end += offset
+	}
+
+	for start < offset && strings.ContainsRune(" \t-*+>#", rune(content[start])) {
+		start++
+	}
+	for end > offset && (content[end-1] == '\n' || content[end-1] == ' ') {
+		end--
+	}
+	return span{start: start, end: end}
+}
+
+// loadBacklinks reads the backlinks of the current note for the panel.
+func (m *Model) loadBacklinks() {
+	m.backlinks = nil
+	m.backlinkCursor = 0
+	if path := m.currentNotePath(); path != "" {
+		m.backlinks = m.index.Backlinks(path)
+	}
+}
+
+func (m Model) updateBacklinks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys[msg.String()] {
+	case actionClear, actionBacklinks:
+		m.showBacklinks = false
+	case actionUp:
+		if m.backlinkCursor > 0 {
+			m.backlinkCursor--
+		}
+	case actionDown:
+		if m.backlinkCursor < len(m.backlinks)-1 {
+			m.backlinkCursor++
+		}
+	case actionOpen:
+		if m.backlinkCursor < len(m.backlinks) {
+			m.openBacklink(m.backlinks[m.backlinkCursor])
+		}
+		m.showBacklinks = false
+	}
+	return m, nil
+}
+
+// openBacklink opens the linking note and highlights the paragraph that
+// contains the link.
+func (m *Model) openBacklink(backlink Backlink) {
+	from := m.currentNotePath()
+	if !m.openNote(backlink.Path) {
+		return
+	}
+	if from != "" {
+		m.history = append(m.history, from)
+		m.forward = nil
+	}
+
+	content := m.notes[m.cursor].content
+	if backlink.Offset < len(content) {
+		p := paragraphSpan(content, backlink.Offset)
+		m.paragraph = &p
+		if line := m.renderPreview(); line >= 0 {
+			m.viewport.SetYOffset(line)
+		}
+	}
+}
+
+func (m Model) formatBacklinks(width, height int) string {
+	title := "Backlinks"
+	if path := m.currentNotePath(); path != "" {
+		title = fmt.Sprintf("Backlinks to %s", m.notes[m.cursor].title)
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(ansi.Truncate(title, width, "…")), ""}
+
+	start := 0
+	if visible := (height - len(lines)) / 2; visible > 0 && m.backlinkCursor >= visible {
+		start = m.backlinkCursor - visible + 1
+	}
+	for i := start; i < len(m.backlinks); i++ {
+		backlink := m.backlinks[i]
+		if len(lines)+2 > height {
+			break
+		}
+		style := lipgloss.NewStyle()
+		if i == m.backlinkCursor {
+			style = style.Foreground(m.styles.highlight).Bold(true)
+		}
+		lines = append(lines,
+			style.Render(ansi.Truncate(backlink.Title, width, "…")),
+			m.styles.muted.Render(ansi.Truncate("  "+backlink.Context, width, "…")),
+		)
+	}
+	if len(m.backlinks) == 0 {
+		lines = append(lines, m.styles.muted.Render("No notes link here"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexBacklinks(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		"target.md":       "# Target\n",
+		"a.md":            "# A\nIntro\n\nSee [[Target]] for details.\n",
+		"nested/b.md":     "# B\n- links to [[../target]]\n",
+		"c.md":            "# C\nNo links, just [[Missing]]\n",
+		"self/target2.md": "# Self\n[[target2]]\n",
+	})
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(root, func(string) bool { return false })
+
+	backlinks := idx.Backlinks(filepath.Join(root, "target.md"))
+	if len(backlinks) != 2 {
+		t.Fatalf("Backlinks() returned %d backlinks, want 2: %+v", len(backlinks), backlinks)
+	}
+	if backlinks[0].Path != filepath.Join(root, "a.md") || backlinks[0].Context != "See [[Target]] for details." {
+		t.Errorf("first backlink = %+v", backlinks[0])
+	}
+	if backlinks[1].Path != filepath.Join(root, "nested", "b.md") || backlinks[1].Context != "- links to [[../target]]" {
+		t.Errorf("second backlink = %+v", backlinks[1])
+	}
+
+	if self := idx.Backlinks(filepath.Join(root, "self", "target2.md")); len(self) != 0 {
+		t.Errorf("a note linking to itself should not be its own backlink: %+v", self)
+	}
+}
+
+func TestParagraphSpan(t *testing.T) {
+	content := "# Title\n\n- first line\n  with [[link]]\n\nNext paragraph"
+	offset := len("# Title\n\n- first line\n  with ")
+	p := paragraphSpan(content, offset)
+	if got := content[p.start:p.end]; got != "first line\n  with [[link]]" {
+		t.Errorf("paragraphSpan() = %q", got)
+	}
+}
+
+func TestLinkedFromFollowsChanges(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"a.md": "# A\n[[Target]] and [[target]] again\n",
+		"b.md": "# B\n",
+	})
+	idx := newIndex("", root)
+	idx.Refresh(".", func(string) bool { return false })
+	if got := idx.LinkedFrom("target.md"); len(got) != 0 {
+		t.Fatalf("LinkedFrom() = %v before the target exists", got)
+	}
+
+	writeNotes(t, root, map[string]string{"target.md": "# Target\n"})
+	idx.Refresh(".", func(string) bool { return false })
+	if got := idx.LinkedFrom("target.md"); len(got) != 1 || got[0] != "a.md" {
+		t.Errorf("LinkedFrom() = %v, want [a.md]", got)
+	}
+
+	writeNotes(t, root, map[string]string{"b.md": "# B\nSee [[Target]]\n"})
+	future := time.Now().Add(time.Hour)
+	os.Chtimes("b.md", future, future)
+	info, err := os.Stat("b.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Update("b.md", info)
+	if got := idx.LinkedFrom("target.md"); len(got) != 2 || got[1] != "b.md" {
+		t.Errorf("LinkedFrom() = %v after b.md links to the target", got)
+	}
+}
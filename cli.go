@@ -0,0 +1,548 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commands are the non-interactive subcommands, run as `note <name> ...`.
+// Each returns the process exit code: 1 on failure and 2 on bad usage.
+var commands = map[string]func(args []string) int{
+	"new":     runNew,
+	"add":     runAdd,
+	"ls":      runList,
+	"cat":     runCat,
+	"edit":    runEdit,
+	"mv":      runMove,
+	"archive": runArchive,
+	"search":  runSearch,
+	"export":  runExport,
+	"today":   runToday,
+	"journal": runJournal,
+}
+
+// noteJSON is how a note is printed with --json.
+type noteJSON struct {
+	Path     string    `json:"path"` // relative to the notes dir
+	File     string    `json:"file"` // absolute path
+	Title    string    `json:"title"`
+	Tags     []string  `json:"tags"`
+	Modified time.Time `json:"modified"`
+	Content  string    `json:"content,omitempty"`
+}
+
+func (v *vault) noteJSON(path string) noteJSON {
+	note := noteJSON{
+		Path:  path,
+		File:  filepath.Join(v.cfg.NotesDir, path),
+		Title: strings.TrimSuffix(filepath.Base(path), ".md"),
+		Tags:  []string{},
+	}
+	if entry, ok := v.index.Entries[path]; ok {
+		note.Title = entry.Title
+		note.Modified = time.Unix(0, entry.ModTime)
+		if entry.Tags != nil {
+			note.Tags = entry.Tags
+		}
+	}
+	return note
+}
+
+func printJSON(value interface{}) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// newFlags creates the flag set of a subcommand with the shared --json flag.
+func newFlags(name, usage string) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	jsonOut := flags.Bool("json", false, "Print machine-readable JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: note "+name+" "+usage)
+		flags.PrintDefaults()
+	}
+	return flags, jsonOut
+}
+
+// parseArgs parses flags wherever they appear among the arguments, so that
+// `note cat inbox --json` works, and returns the positional arguments.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err)
+	return 1
+}
+
+func runNew(args []string) int {
+	flags, jsonOut := newFlags("new", "[--folder DIR] [--json] [TITLE...]")
+	folder := flags.String("folder", ".", "Folder in the notes dir to create the note in")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	dir, err := v.insidePath(*folder)
+	if err != nil {
+		return fail(err)
+	}
+	path, err := createNote(dir, strings.Join(rest, " "), "")
+	if err != nil {
+		return fail(err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		v.index.Update(path, info)
+		v.index.Save()
+	}
+
+	if *jsonOut {
+		return printJSON(v.noteJSON(path))
+	}
+	fmt.Println(path)
+	return 0
+}
+
+// runAdd captures text from the arguments or stdin, either as a new note or
+// as a timestamped bullet appended to an existing one.
+func runAdd(args []string) int {
+	flags, jsonOut := newFlags("add", "[--title TITLE] [--folder DIR | --append NOTE] [--json] [TEXT...]")
+	title := flags.String("title", "", "Title of the new note")
+	folder := flags.String("folder", ".", "Folder in the notes dir to create the note in")
+	appendTo := flags.String("append", "", "Append the text as a bullet to this note instead")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	text := strings.Join(rest, " ")
+	if text == "" {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fail(err)
+			}
+			text = string(data)
+		}
+	}
+	if strings.TrimSpace(text) == "" && (*appendTo != "" || *title == "") {
+		fmt.Fprintln(os.Stderr, "Nothing to add: pass the text as arguments or on stdin")
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	var path string
+	if *appendTo != "" {
+		if path, err = v.resolve(*appendTo); err != nil {
+			return fail(err)
+		}
+		err = appendBullet(path, text)
+	} else {
+		var dir string
+		if dir, err = v.insidePath(*folder); err == nil {
+			path, err = createNote(dir, *title, text)
+		}
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		v.index.Update(path, info)
+		v.index.Save()
+	}
+
+	if *jsonOut {
+		return printJSON(v.noteJSON(path))
+	}
+	fmt.Println(path)
+	return 0
+}
+
+func runList(args []string) int {
+	flags, jsonOut := newFlags("ls", "[--json] [FOLDER]")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	folder := "."
+	if len(rest) == 1 {
+		if folder, err = v.resolve(rest[0]); err != nil {
+			return fail(err)
+		}
+	}
+
+	var paths []string
+	for path := range v.index.Entries {
+		if folder == "." || path == folder || strings.HasPrefix(path, folder+string(filepath.Separator)) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	if *jsonOut {
+		notes := make([]noteJSON, 0, len(paths))
+		for _, path := range paths {
+			notes = append(notes, v.noteJSON(path))
+		}
+		return printJSON(notes)
+	}
+	for _, path := range paths {
+		fmt.Printf("%s\t%s\n", path, v.index.Entries[path].Title)
+	}
+	return 0
+}
+
+func runCat(args []string) int {
+	flags, jsonOut := newFlags("cat", "[--json] NOTE")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	path, err := v.resolve(rest[0])
+	if err != nil {
+		return fail(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fail(err)
+	}
+
+	if *jsonOut {
+		note := v.noteJSON(path)
+		note.Content = string(content)
+		return printJSON(note)
+	}
+	os.Stdout.Write(content)
+	return 0
+}
+
+func runEdit(args []string) int {
+	flags, _ := newFlags("edit", "[--line N] NOTE[#HEADING]")
+	line := flags.Int("line", 0, "Line to open the note at")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	// A "#" may be part of a file name rather than start a heading
+	target, heading, _ := strings.Cut(rest[0], "#")
+	path, err := v.resolve(target)
+	if err != nil && heading != "" {
+		heading = ""
+		path, err = v.resolve(rest[0])
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if heading != "" && *line == 0 {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fail(err)
+		}
+		if *line = headingLine(string(content), heading); *line == 0 {
+			return fail(fmt.Errorf("%s has no heading %q", path, heading))
+		}
+	}
+
+	cmd, err := editorCommand(v.cfg.GetEditor(), path, *line)
+	if err != nil {
+		return fail(err)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func runMove(args []string) int {
+	flags, jsonOut := newFlags("mv", "[--no-links] [--json] SOURCE DEST")
+	noLinks := flags.Bool("no-links", false, "Do not update links pointing at the moved note")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	from, err := v.resolve(rest[0])
+	if err != nil {
+		return fail(err)
+	}
+	to, err := moveTarget(v, from, rest[1])
+	if err != nil {
+		return fail(err)
+	}
+
+	plan := v.index.planRename(from, to)
+	if err := plan.Apply(!*noLinks); err != nil {
+		return fail(err)
+	}
+	v.index.Refresh(".", v.cfg.isArchiveDir)
+	v.index.Save()
+
+	updated := len(plan.Edits)
+	if *noLinks {
+		updated = 0
+	}
+	if *jsonOut {
+		return printJSON(struct {
+			From         string `json:"from"`
+			To           string `json:"to"`
+			LinksUpdated int    `json:"links_updated"`
+		}{from, to, updated})
+	}
+	fmt.Printf("%s -> %s (%d links updated)\n", from, to, updated)
+	return 0
+}
+
+// moveTarget works out where `note mv` puts from: inside dest if dest is an
+// existing folder, otherwise at dest in the notes dir, keeping the .md
+// extension of notes. Destinations outside the notes dir are rejected.
+func moveTarget(v *vault, from, dest string) (string, error) {
+	if dir, err := vaultPath(v.cfg, dest); err == nil {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return filepath.Join(dir, filepath.Base(from)), nil
+		}
+	}
+	to, err := v.insidePath(dest)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(from, ".md") && !strings.HasSuffix(to, ".md") {
+		to += ".md"
+	}
+	return to, nil
+}
+
+func runArchive(args []string) int {
+	if len(args) > 0 && args[0] == "purge" {
+		return runArchivePurge(args[1:])
+	}
+
+	flags, jsonOut := newFlags("archive", "[--json] NOTE... | purge [--dry-run]")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	type archived struct {
+		Path    string `json:"path"`
+		Archive string `json:"archive"`
+	}
+	// A note that cannot be archived does not stop the others, and the
+	// index is saved for whatever was moved
+	status := 0
+	var results []archived
+	for _, arg := range rest {
+		path, err := v.resolve(arg)
+		if err != nil {
+			status = fail(err)
+			continue
+		}
+		archivePath, err := archiveNote(v.cfg, path)
+		if err != nil && archivePath == "" {
+			status = fail(fmt.Errorf("could not archive %s: %v", path, err))
+			continue
+		}
+		if err != nil {
+			status = fail(fmt.Errorf("archived %s but could not record where it came from: %v", path, err))
+		}
+		results = append(results, archived{path, archivePath})
+	}
+	v.index.Refresh(".", v.cfg.isArchiveDir)
+	if err := v.index.Save(); err != nil {
+		status = fail(err)
+	}
+
+	if *jsonOut {
+		if results == nil {
+			results = []archived{}
+		}
+		if printJSON(results) != 0 {
+			return 1
+		}
+		return status
+	}
+	for _, result := range results {
+		fmt.Printf("%s -> %s\n", result.Path, result.Archive)
+	}
+	return status
+}
+
+// runArchivePurge enforces the archive retention policy from the config.
+func runArchivePurge(args []string) int {
+	flags, jsonOut := newFlags("archive purge", "[--dry-run] [--json]")
+	dryRun := flags.Bool("dry-run", false, "Only list what would be deleted or compressed")
+	if _, err := parseArgs(flags, args); err != nil {
+		return 2
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return fail(err)
+	}
+	if !cfg.ArchiveRetention.enabled() {
+		fmt.Fprintln(os.Stderr, "No archive_retention limits are configured")
+		return 1
+	}
+	actions, err := enforceRetention(cfg, time.Now(), *dryRun)
+	if err != nil {
+		return fail(err)
+	}
+
+	if *jsonOut {
+		type result struct {
+			Name     string `json:"name"`
+			Original string `json:"original"`
+			Action   string `json:"action"`
+			Reason   string `json:"reason"`
+		}
+		out := make([]result, 0, len(actions))
+		for _, action := range actions {
+			verb := "delete"
+			if action.Compress {
+				verb = "compress"
+			}
+			out = append(out, result{action.Entry.Name, action.Entry.Original, verb, action.Reason})
+		}
+		return printJSON(out)
+	}
+	for _, action := range actions {
+		verb := "Deleted"
+		if action.Compress {
+			verb = "Compressed"
+		}
+		if *dryRun {
+			verb = "Would delete"
+			if action.Compress {
+				verb = "Would compress"
+			}
+		}
+		fmt.Printf("%s %s (%s)\n", verb, action.Entry.Name, action.Reason)
+	}
+	return 0
+}
+
+func runSearch(args []string) int {
+	flags, jsonOut := newFlags("search", "[--json] QUERY...")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	results := v.index.Search(strings.Join(rest, " "))
+
+	if *jsonOut {
+		type match struct {
+			Line int    `json:"line"`
+			Text string `json:"text"`
+		}
+		type result struct {
+			noteJSON
+			Score   int     `json:"score"`
+			Matches []match `json:"matches"`
+		}
+		out := make([]result, 0, len(results))
+		for _, r := range results {
+			matches := make([]match, 0, len(r.Snippets))
+			for _, snippet := range r.Snippets {
+				matches = append(matches, match{snippet.Line, snippet.Text})
+			}
+			out = append(out, result{v.noteJSON(r.Path), r.Score, matches})
+		}
+		return printJSON(out)
+	}
+	for _, r := range results {
+		if len(r.Snippets) == 0 {
+			fmt.Println(r.Path)
+		}
+		for _, snippet := range r.Snippets {
+			fmt.Printf("%s:%d:%s\n", r.Path, snippet.Line, snippet.Text)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantJSON bool
+		want     []string
+	}{
+		{"flags first", []string{"--json", "inbox"}, true, []string{"inbox"}},
+		{"flags after arguments", []string{"inbox", "--json", "todo"}, true, []string{"inbox", "todo"}},
+		{"no flags", []string{"a", "b"}, false, []string{"a", "b"}},
+		{"terminator", []string{"a", "--", "--json"}, false, []string{"a", "--json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			jsonOut := flags.Bool("json", false, "")
+			got, err := parseArgs(flags, tt.args)
+			if err != nil {
+				t.Fatalf("parseArgs() error = %v", err)
+			}
+			if *jsonOut != tt.wantJSON || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArgs() = %v, json %v; want %v, json %v", got, *jsonOut, tt.want, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestVault(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"inbox.md":          "# Inbox\n",
+		"projects/alpha.md": "# Project Alpha\n",
+	})
+	cfg := DefaultConfig()
+	cfg.NotesDir = root
+	cfg.ArchiveDir = filepath.Join(root, "archive")
+	v := &vault{cfg: cfg, index: newIndex(filepath.Join(t.TempDir(), "index.gob"), root), wd: t.TempDir()}
+	v.index.Refresh(".", cfg.isArchiveDir)
+
+	for arg, want := range map[string]string{
+		"inbox.md":      "inbox.md",
+		"projects":      "projects",
+		"Project Alpha": filepath.Join("projects", "alpha.md"),
+	} {
+		if got, err := v.resolve(arg); err != nil || got != want {
+			t.Errorf("resolve(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+	if _, err := v.resolve("missing"); err == nil {
+		t.Errorf("resolve(missing) should fail")
+	}
+
+	if got, err := moveTarget(v, "inbox.md", "projects"); err != nil || got != filepath.Join("projects", "inbox.md") {
+		t.Errorf("moveTarget into folder = %q, %v", got, err)
+	}
+	if got, err := moveTarget(v, "inbox.md", "later"); err != nil || got != "later.md" {
+		t.Errorf("moveTarget rename = %q, %v; want later.md", got, err)
+	}
+	if got, err := moveTarget(v, "inbox.md", filepath.Join(root, "projects", "beta")); err != nil || got != filepath.Join("projects", "beta.md") {
+		t.Errorf("moveTarget absolute = %q, %v", got, err)
+	}
+	if _, err := v.insidePath(filepath.Join("..", "x")); err == nil {
+		t.Errorf("insidePath(../x) should fail")
+	}
+	if got, err := v.insidePath(filepath.Join(root, "journal")); err != nil || got != "journal" {
+		t.Errorf("insidePath(absolute) = %q, %v", got, err)
+	}
+	for _, dest := range []string{"../elsewhere", filepath.Join("projects", "..", "..", "x"), filepath.Dir(root)} {
+		if got, err := moveTarget(v, "inbox.md", dest); err == nil {
+			t.Errorf("moveTarget(%q) = %q, want an error for leaving the notes dir", dest, got)
+		}
+	}
+
+	path, err := createNote("journal", "Standup", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); !strings.HasPrefix(string(content), "# Standup\n") {
+		t.Errorf("createNote() content = %q", content)
+	}
+
+	archived, err := archiveNote(cfg, "inbox.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(archived); err != nil || filepath.Dir(archived) != cfg.ArchiveDir {
+		t.Errorf("archiveNote() = %q, %v", archived, err)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+
+	path, err := createNote("inbox", "Ideas", "  first thought\nsecond\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "# Ideas\n\nCreated: ") || !strings.HasSuffix(string(content), "\n\nfirst thought\nsecond\n") {
+		t.Errorf("createNote() content = %q", content)
+	}
+	if again, err := createNote("inbox", "Ideas", ""); err != nil || again == path {
+		t.Errorf("second createNote() = %q, %v; want a new file", again, err)
+	}
+
+	os.WriteFile("log.md", []byte("# Log"), 0644)
+	if err := appendBullet("log.md", "shipped it\nwith notes"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile("log.md")
+	lines := strings.Split(string(content), "\n")
+	if len(lines) != 4 || lines[0] != "# Log" || !strings.HasPrefix(lines[1], "- ") || !strings.HasSuffix(lines[1], " shipped it") || lines[2] != "  with notes" {
+		t.Errorf("appendBullet() content = %q", content)
+	}
+}
@@ -24,15 +24,26 @@ type Layout struct {
 }
 
 type Config struct {
-	ConfigDir     string `yaml:"config_dir"`
-	NotesDir      string `yaml:"notes_dir"`
-	ArchiveDir    string `yaml:"archive_dir"`
-	Editor        string `yaml:"editor"`
-	Layout        Layout `yaml:"layout"`
-	Theme         struct {
+	ConfigDir          string     `yaml:"config_dir"`
+	NotesDir           string     `yaml:"notes_dir"`
+	ArchiveDir         string     `yaml:"archive_dir"`
+	ArchiveRetention   Retention  `yaml:"archive_retention"`
+	Editor             string     `yaml:"editor"`
+	InlineEditor       bool       `yaml:"inline_editor"` // enter edits in the content pane
+	PreviewLinkUpdates bool       `yaml:"preview_link_updates"`
+	DebugLog           bool       `yaml:"debug_log"` // append notifications to ConfigDir/debug.log
+	Watch              string     `yaml:"watch"`     // auto, poll or off
+	Daily              Daily      `yaml:"daily"`
+	Sort               SortConfig `yaml:"sort"`
+	Layout             Layout     `yaml:"layout"`
+	Theme              struct {
 		Light string `yaml:"light"`
 		Dark  string `yaml:"dark"`
 	} `yaml:"theme"`
+	// Keys maps actions to the keys that trigger them; see keys.go
+	Keys map[string][]string `yaml:"keys"`
+
+	keyWarnings []string // default keys that gave way to the user's bindings
 }
 
 type Dimensions struct {
@@ -69,10 +80,16 @@ func getDataHome() string {
 func DefaultConfig() *Config {
 	configDir, _ := getConfigDir()
 	return &Config{
-		ConfigDir:     configDir,
-		NotesDir:      filepath.Join(getDataHome(), "note"),
-		ArchiveDir:    filepath.Join(getDataHome(), "note", "archive"),
-		Editor:        "",
+		ConfigDir:          configDir,
+		NotesDir:           filepath.Join(getDataHome(), "note"),
+		ArchiveDir:         filepath.Join(getDataHome(), "note", "archive"),
+		Editor:             "",
+		PreviewLinkUpdates: true,
+		Watch:              watchAuto,
+		Daily:              Daily{Dir: "journal"},
+		Sort: SortConfig{
+			Default: SortOrder{Mode: SortFilename, FoldersFirst: true},
+		},
 		Layout: Layout{
 			SidebarWidth: 30,
 			Padding: struct {
@@ -99,9 +116,10 @@ func DefaultConfig() *Config {
 			Light string `yaml:"light"`
 			Dark  string `yaml:"dark"`
 		}{
-			Light: "default",
+			Light: "light",
 			Dark:  "default",
 		},
+		Keys: DefaultKeys(),
 	}
 }
 
@@ -114,6 +132,7 @@ func LoadConfig() (*Config, error) {
 		configDir,
 		cfg.NotesDir,
 		cfg.ArchiveDir,
+		themesDir(configDir),
 	}
 
 	for _, dir := range dirs {
@@ -150,6 +169,20 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	var file struct {
+		Keys map[string][]string `yaml:"keys"`
+	}
+	yaml.Unmarshal(data, &file)
+	cfg.Keys, cfg.keyWarnings = mergeKeys(file.Keys)
+	if _, err := NewKeyMap(cfg.Keys); err != nil {
+		return nil, fmt.Errorf("invalid keys in %s: %v", configPath, err)
+	}
+	switch cfg.Watch {
+	case watchAuto, watchPoll, watchOff:
+	default:
+		return nil, fmt.Errorf("invalid watch mode %q in %s: use auto, poll or off", cfg.Watch, configPath)
+	}
+
 	return cfg, nil
 }
 
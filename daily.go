@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Daily configures daily notes, kept as Dir/YYYY/MM/YYYY-MM-DD.md in the
+// notes dir.
+type Daily struct {
+	Dir string `yaml:"dir"`
+	// Template is a text/template file, relative to the config dir, for new
+	// daily notes. See dailyTemplateData for what it can use.
+	Template string `yaml:"template"`
+}
+
+const defaultDailyTemplate = "# {{.Title}}\n\n"
+
+// dailyTemplateData is what a daily note template is executed with.
+type dailyTemplateData struct {
+	Date      time.Time
+	Title     string // e.g. "Saturday, October 17, 2026"
+	Yesterday string // YYYY-MM-DD, which [[links]] resolve to the daily note
+	Tomorrow  string
+}
+
+const dayLayout = "2006-01-02"
+
+// dailyPath is where the daily note for date lives, relative to the notes
+// dir.
+func (d Daily) dailyPath(date time.Time) string {
+	return filepath.Join(d.Dir, date.Format("2006"), date.Format("01"), date.Format(dayLayout)+".md")
+}
+
+// parseDailyPath returns the date of the daily note at path, if it is one.
+func (d Daily) parseDailyPath(path string) (time.Time, bool) {
+	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	date, err := time.ParseInLocation(dayLayout, name, time.Local)
+	if err != nil || filepath.Clean(path) != d.dailyPath(date) {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// template reads the configured template, or the default one if none is
+// set.
+func (d Daily) template(configDir string) (*template.Template, error) {
+	text := defaultDailyTemplate
+	if d.Template != "" {
+		path := d.Template
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(configDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	return template.New("daily").Parse(text)
+}
+
+// openDaily returns the daily note for date, creating it from the template
+// if it does not exist yet.
+func openDaily(cfg *Config, date time.Time) (path string, created bool, err error) {
+	path = cfg.Daily.dailyPath(date)
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+
+	tmpl, err := cfg.Daily.template(cfg.ConfigDir)
+	if err != nil {
+		return "", false, fmt.Errorf("daily note template: %v", err)
+	}
+	var content strings.Builder
+	err = tmpl.Execute(&content, dailyTemplateData{
+		Date:      date,
+		Title:     date.Format("Monday, January 2, 2006"),
+		Yesterday: date.AddDate(0, 0, -1).Format(dayLayout),
+		Tomorrow:  date.AddDate(0, 0, 1).Format(dayLayout),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("daily note template: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", false, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := f.WriteString(content.String()); err != nil {
+		f.Close()
+		return "", false, err
+	}
+	return path, true, f.Close()
+}
+
+// dailyDates lists the days that have a daily note, oldest first.
+func (d Daily) dailyDates() []time.Time {
+	var dates []time.Time
+	filepath.WalkDir(d.Dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if date, ok := d.parseDailyPath(path); ok {
+			dates = append(dates, date)
+		}
+		return nil
+	})
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// adjacentDaily finds the closest daily note before (delta < 0) or after
+// from, skipping days without one.
+func (d Daily) adjacentDaily(from time.Time, delta int) (string, bool) {
+	dates := d.dailyDates()
+	if delta < 0 {
+		for i := len(dates) - 1; i >= 0; i-- {
+			if dates[i].Before(from) {
+				return d.dailyPath(dates[i]), true
+			}
+		}
+	} else {
+		for _, date := range dates {
+			if date.After(from) {
+				return d.dailyPath(date), true
+			}
+		}
+	}
+	return "", false
+}
+
+// today is the current date at midnight.
+func today() time.Time {
+	y, mo, day := time.Now().Date()
+	return time.Date(y, mo, day, 0, 0, 0, 0, time.Local)
+}
+
+// weekOf returns the days of the week, Monday to Sunday, that date is in.
+func weekOf(date time.Time) []time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	monday := date.AddDate(0, 0, -offset)
+	days := make([]time.Time, 7)
+	for i := range days {
+		days[i] = monday.AddDate(0, 0, i)
+	}
+	return days
+}
+
+// dailyEntry is the body of a daily note without its frontmatter and title
+// heading.
+func dailyEntry(content string) string {
+	_, body := parseFrontmatter(content)
+	body = strings.TrimLeft(body, "\n")
+	if strings.HasPrefix(body, "# ") {
+		if _, rest, ok := strings.Cut(body, "\n"); ok {
+			body = rest
+		} else {
+			body = ""
+		}
+	}
+	return strings.TrimSpace(body)
+}
+
+// openDailyNote selects the daily note for date in the tree, creating it if
+// needed.
+func (m *Model) openDailyNote(date time.Time) {
+	path, created, err := openDaily(m.config, date)
+	if err != nil {
+		m.notifyError("Could not create the daily note: %v", err)
+		return
+	}
+	if created {
+		content, _ := os.ReadFile(path)
+		m.record(Operation{Kind: opCreateNote, Path: path, Content: string(content)})
+		m.notify("Created %s", path)
+		if info, err := os.Stat(path); err == nil {
+			m.index.Update(path, info)
+		}
+		m.updateNotes()
+	}
+	m.revealNote(path)
+	m.updatePreview()
+}
+
+// stepDaily moves to the previous or next daily note from the selected one,
+// or from today when the selection is not a daily note.
+func (m *Model) stepDaily(delta int) {
+	from, ok := m.config.Daily.parseDailyPath(m.currentNotePath())
+	if !ok {
+		from = today()
+	}
+	path, ok := m.config.Daily.adjacentDaily(from, delta)
+	if !ok {
+		direction := "later"
+		if delta < 0 {
+			direction = "earlier"
+		}
+		m.notify("No %s daily note than %s", direction, from.Format(dayLayout))
+		return
+	}
+	m.revealNote(path)
+	m.updatePreview()
+}
+
+// runToday opens today's daily note in the editor, creating it first if
+// needed.
+func runToday(args []string) int {
+	flags, jsonOut := newFlags("today", "[--print] [--json]")
+	printPath := flags.Bool("print", false, "Print the path instead of opening the editor")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	path, created, err := openDaily(v.cfg, today())
+	if err != nil {
+		return fail(err)
+	}
+	if created {
+		if info, err := os.Stat(path); err == nil {
+			v.index.Update(path, info)
+			v.index.Save()
+		}
+	}
+
+	if *jsonOut {
+		return printJSON(v.noteJSON(path))
+	}
+	if *printPath {
+		fmt.Println(path)
+		return 0
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fail(err)
+	}
+	cmd, err := editorCommand(v.cfg.GetEditor(), path, strings.Count(strings.TrimRight(string(content), "\n"), "\n")+1)
+	if err != nil {
+		return fail(err)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// dailyJSON is a daily note printed by `note journal --json`.
+type dailyJSON struct {
+	Date string `json:"date"`
+	noteJSON
+}
+
+// runJournal prints the daily note of a day, or with --week every daily
+// note of its week.
+func runJournal(args []string) int {
+	flags, jsonOut := newFlags("journal", "[--week] [--date YYYY-MM-DD] [--json]")
+	week := flags.Bool("week", false, "Summarize the whole week, Monday to Sunday")
+	dateFlag := flags.String("date", "", "Day to show instead of today")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 0 {
+		flags.Usage()
+		return 2
+	}
+	date := today()
+	if *dateFlag != "" {
+		if date, err = time.ParseInLocation(dayLayout, *dateFlag, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date %q: use YYYY-MM-DD\n", *dateFlag)
+			return 2
+		}
+	}
+
+	v, err := openVault()
+	if err != nil {
+		return fail(err)
+	}
+	days := []time.Time{date}
+	if *week {
+		days = weekOf(date)
+	}
+
+	var entries []dailyJSON
+	for _, day := range days {
+		path := v.cfg.Daily.dailyPath(day)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		entry := dailyJSON{Date: day.Format(dayLayout), noteJSON: v.noteJSON(path)}
+		entry.Content = string(content)
+		entries = append(entries, entry)
+	}
+	if !*week && len(entries) == 0 {
+		return fail(fmt.Errorf("no daily note for %s", date.Format(dayLayout)))
+	}
+
+	if *jsonOut {
+		if entries == nil {
+			entries = []dailyJSON{}
+		}
+		return printJSON(entries)
+	}
+	if !*week {
+		fmt.Print(entries[0].Content)
+		return 0
+	}
+	fmt.Print(weekSummary(days, entries))
+	return 0
+}
+
+// weekSummary lists each day of the week with the entry of its daily note.
+func weekSummary(days []time.Time, entries []dailyJSON) string {
+	var b strings.Builder
+	_, number := days[0].ISOWeek()
+	fmt.Fprintf(&b, "Week %d: %s to %s\n", number, days[0].Format("Jan 2"), days[len(days)-1].Format("Jan 2, 2006"))
+	for _, day := range days {
+		fmt.Fprintf(&b, "\n## %s\n", day.Format("Monday, January 2"))
+		var entry *dailyJSON
+		for i := range entries {
+			if entries[i].Date == day.Format(dayLayout) {
+				entry = &entries[i]
+			}
+		}
+		switch {
+		case entry == nil:
+			b.WriteString("No entry\n")
+		case dailyEntry(entry.Content) == "":
+			fmt.Fprintf(&b, "%s is empty\n", entry.Path)
+		default:
+			b.WriteString(dailyEntry(entry.Content) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	date, _ := time.ParseInLocation(dayLayout, s, time.Local)
+	return date
+}
+
+func TestDailyPath(t *testing.T) {
+	d := Daily{Dir: "journal"}
+	path := d.dailyPath(day("2026-03-09"))
+	if want := filepath.Join("journal", "2026", "03", "2026-03-09.md"); path != want {
+		t.Errorf("dailyPath() = %q, want %q", path, want)
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{filepath.Join("journal", "2026", "03", "2026-03-09.md"), true},
+		{filepath.Join("journal", "2026", "04", "2026-03-09.md"), false},
+		{"2026-03-09.md", false},
+		{filepath.Join("journal", "2026", "03", "notes.md"), false},
+	}
+	for _, tt := range tests {
+		if date, ok := d.parseDailyPath(tt.path); ok != tt.ok || (ok && !date.Equal(day("2026-03-09"))) {
+			t.Errorf("parseDailyPath(%q) = %v, %v; want ok %v", tt.path, date, ok, tt.ok)
+		}
+	}
+}
+
+func TestWeekOf(t *testing.T) {
+	for _, date := range []string{"2026-10-12", "2026-10-15", "2026-10-18"} {
+		week := weekOf(day(date))
+		if week[0].Format(dayLayout) != "2026-10-12" || week[6].Format(dayLayout) != "2026-10-18" {
+			t.Errorf("weekOf(%s) = %s to %s", date, week[0].Format(dayLayout), week[6].Format(dayLayout))
+		}
+	}
+}
+
+func TestOpenDaily(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	cfg := DefaultConfig()
+	cfg.ConfigDir = t.TempDir()
+
+	path, created, err := openDaily(cfg, day("2026-10-17"))
+	if err != nil || !created {
+		t.Fatalf("openDaily() = %q, %v, %v", path, created, err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "# Saturday, October 17, 2026\n\n" {
+		t.Errorf("default template gave %q", content)
+	}
+	os.WriteFile(path, []byte("# Today\n\n- wrote tests\n"), 0644)
+	if _, created, _ := openDaily(cfg, day("2026-10-17")); created {
+		t.Errorf("an existing daily note should be opened, not recreated")
+	}
+
+	os.WriteFile(filepath.Join(cfg.ConfigDir, "daily.md"), []byte("---\ntags: [daily]\n---\n# {{.Date.Format \"Jan 2\"}}\n\n[[{{.Yesterday}}]]\n"), 0644)
+	cfg.Daily.Template = "daily.md"
+	path, _, err = openDaily(cfg, day("2026-10-12"))
+	if content, _ := os.ReadFile(path); err != nil || !strings.Contains(string(content), "# Oct 12\n\n[[2026-10-11]]") {
+		t.Errorf("custom template gave %q, %v", content, err)
+	}
+
+	if path, ok := cfg.Daily.adjacentDaily(day("2026-10-17"), -1); !ok || path != cfg.Daily.dailyPath(day("2026-10-12")) {
+		t.Errorf("previous daily note = %q, %v", path, ok)
+	}
+	if _, ok := cfg.Daily.adjacentDaily(day("2026-10-17"), 1); ok {
+		t.Errorf("there is no later daily note")
+	}
+
+	var entries []dailyJSON
+	for _, date := range []string{"2026-10-12", "2026-10-17"} {
+		path := cfg.Daily.dailyPath(day(date))
+		content, _ := os.ReadFile(path)
+		entries = append(entries, dailyJSON{Date: date, noteJSON: noteJSON{Path: path, Content: string(content)}})
+	}
+	entries = append(entries, dailyJSON{Date: "2026-10-13", noteJSON: noteJSON{Path: "13.md", Content: "# Tuesday\n"}})
+	summary := weekSummary(weekOf(day("2026-10-17")), entries)
+	for _, want := range []string{"Week 42", "## Monday, October 12\n[[2026-10-11]]", "## Tuesday, October 13\n13.md is empty", "## Wednesday, October 14\nNo entry", "## Saturday, October 17\n- wrote tests"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("week summary is missing %q:\n%s", want, summary)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lineFlagEditors take "+LINE" before the file to open it at a line.
+var lineFlagEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true, "micro": true, "kak": true,
+}
+
+// splitCommand splits a command line into words the way a POSIX shell does
+// for quoting: single quotes are literal, double quotes allow backslash
+// escapes of \ " $ and `, and a backslash outside quotes escapes any rune.
+func splitCommand(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`\"$`+"`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// editorArgs builds the argument list to open file at line (0 for none) with
+// the editor command. The {file} and {line} placeholders are replaced where
+// they appear; without {file} the file is appended, and editors known to
+// take "+LINE" get one when there is no {line}.
+func editorArgs(editor, file string, line int) ([]string, error) {
+	words, err := splitCommand(editor)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no editor configured")
+	}
+
+	lineText := "1"
+	if line > 0 {
+		lineText = strconv.Itoa(line)
+	}
+	hasFile, hasLine := false, false
+	args := make([]string, 0, len(words)+2)
+	for _, word := range words {
+		hasFile = hasFile || strings.Contains(word, "{file}")
+		hasLine = hasLine || strings.Contains(word, "{line}")
+		word = strings.ReplaceAll(word, "{file}", file)
+		args = append(args, strings.ReplaceAll(word, "{line}", lineText))
+	}
+	if !hasFile {
+		if line > 0 && !hasLine && lineFlagEditors[filepath.Base(args[0])] {
+			args = append(args, "+"+lineText)
+		}
+		args = append(args, file)
+	}
+	return args, nil
+}
+
+func editorCommand(editor, file string, line int) (*exec.Cmd, error) {
+	args, err := editorArgs(editor, file, line)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// headingLine returns the 1-based line of the first markdown heading whose
+// text matches heading, ignoring case, or 0 if there is none.
+func headingLine(content, heading string) int {
+	heading = strings.ToLower(strings.TrimSpace(heading))
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		if strings.ToLower(text) == heading {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// editorFinishedMsg is sent when the editor launched for path exits.
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+// editLine is the source line the editor should open at: the highlighted
+// link or backlink paragraph, if any.
+func (m Model) editLine() int {
+	content := m.notes[m.cursor].content
+	offset := -1
+	if m.activeLink >= 0 && m.activeLink < len(m.links) {
+		offset = m.links[m.activeLink].Start
+	} else if m.paragraph != nil {
+		offset = m.paragraph.start
+	}
+	if offset < 0 || offset > len(content) {
+		return 0
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// openEditor suspends the TUI and edits the selected note.
+func (m *Model) openEditor() tea.Cmd {
+	path := m.notes[m.cursor].path
+	cmd, err := editorCommand(m.config.GetEditor(), path, m.editLine())
+	if err != nil {
+		m.notifyError("Invalid editor command: %v", err)
+		return nil
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{path: path, err: err}
+	})
+}
+
+func (m *Model) editorFinished(msg editorFinishedMsg) {
+	if msg.err != nil {
+		m.notifyError("Editor %s failed: %v", m.config.GetEditor(), msg.err)
+	}
+	m.index.Refresh(".", m.isArchiveDir)
+	m.updateNotes()
+	m.revealNote(msg.path)
+	m.updatePreview()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"vim", []string{"vim"}, false},
+		{"code --wait", []string{"code", "--wait"}, false},
+		{`"/Applications/Sublime Text.app/bin/subl" -w`, []string{"/Applications/Sublime Text.app/bin/subl", "-w"}, false},
+		{`emacsclient -a '' -t`, []string{"emacsclient", "-a", "", "-t"}, false},
+		{`my\ editor "say \"hi\"" 'a\b'`, []string{"my editor", `say "hi"`, `a\b`}, false},
+		{`"unterminated`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := splitCommand(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"code --wait", 0, []string{"code", "--wait", "a b.md"}},
+		{"code --wait --goto {file}:{line}", 12, []string{"code", "--wait", "--goto", "a b.md:12"}},
+		{"code --goto {file}:{line}", 0, []string{"code", "--goto", "a b.md:1"}},
+		{"/usr/bin/vim", 7, []string{"/usr/bin/vim", "+7", "a b.md"}},
+		{"nvim", 0, []string{"nvim", "a b.md"}},
+		{"subl", 7, []string{"subl", "a b.md"}},
+	}
+
+	for _, tt := range tests {
+		got, err := editorArgs(tt.editor, "a b.md", tt.line)
+		if err != nil {
+			t.Fatalf("editorArgs(%q) error = %v", tt.editor, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editorArgs(%q, %d) = %q, want %q", tt.editor, tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := editorArgs("  ", "a.md", 0); err == nil {
+		t.Errorf("editorArgs() with an empty editor should fail")
+	}
+}
+
+func TestHeadingLine(t *testing.T) {
+	content := "# Title\n\nText\n\n## Next Steps\n- a\n"
+	if got := headingLine(content, "next steps"); got != 5 {
+		t.Errorf("headingLine() = %d, want 5", got)
+	}
+	if got := headingLine(content, "missing"); got != 0 {
+		t.Errorf("headingLine(missing) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// imageRe matches inline markdown images such as ![alt](images/a.png "title").
+var imageRe = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+
+const exportStylesheet = `
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #24292f; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; }
+a { color: #7d6bd8; }
+a.broken-link { color: #cf222e; text-decoration: line-through; }
+nav.breadcrumbs { font-size: 0.9rem; margin-bottom: 1rem; }
+pre, code { background: #f6f8fa; border-radius: 4px; }
+pre { padding: 0.8rem; overflow-x: auto; }
+code { padding: 0.1rem 0.3rem; }
+pre code { padding: 0; }
+blockquote { border-left: 4px solid #9d8cff; margin-left: 0; padding-left: 1rem; color: #57606a; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; }
+img { max-width: 100%; }
+.properties { color: #626262; font-size: 0.9rem; }
+ul.tree { list-style: none; padding-left: 1.2rem; }
+ul.tree .folder { font-weight: bold; }
+`
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{.Stylesheet}}</style>
+</head>
+<body>
+<nav class="breadcrumbs"><a href="{{.Home}}">Index</a></nav>
+{{- if .Properties}}
+<p class="properties">{{.Properties}}</p>
+{{- end}}
+{{.Body}}
+</body>
+</html>
+`))
+
+// exportNote is a note selected for export.
+type exportNote struct {
+	path  string // path in the notes dir
+	title string
+}
+
+type exporter struct {
+	index    *Index
+	out      string
+	notes    map[string]exportNote
+	markdown goldmark.Markdown
+}
+
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "html", "Export format (html)")
+	out := flags.String("out", "export", "Directory to write the exported site to")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: note export [--format html] [--out DIR] [NOTE_OR_FOLDER]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "html" {
+		fmt.Fprintf(os.Stderr, "Unsupported export format %q\n", *format)
+		return 2
+	}
+
+	outDir, err := filepath.Abs(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid output directory: %v\n", err)
+		return 1
+	}
+
+	v, err := openVault()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	target := "."
+	if flags.NArg() > 0 {
+		if target, err = v.resolve(flags.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	count, err := exportHTML(v.index, v.cfg, target, outDir, v.cfg.isArchiveDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Exported %d notes to %s\n", count, outDir)
+	return 0
+}
+
+// vaultPath turns a command line path, either relative to the working
+// directory or to the notes dir, into a path inside the notes dir.
+func vaultPath(cfg *Config, arg string) (string, error) {
+	candidates := []string{filepath.Join(cfg.NotesDir, arg)}
+	if abs, err := filepath.Abs(arg); err == nil {
+		candidates = append([]string{abs}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(cfg.NotesDir, candidate)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		return rel, nil
+	}
+	return "", fmt.Errorf("%s is not a note or folder in %s", arg, cfg.NotesDir)
+}
+
+// exportHTML writes target, a note or folder in the current notes dir, to
+// outDir as a static site and returns how many notes were exported.
+func exportHTML(index *Index, cfg *Config, target, outDir string, skip func(string) bool) (int, error) {
+	e := &exporter{
+		index: index,
+		out:   outDir,
+		notes: make(map[string]exportNote),
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM, extension.Footnote),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+	}
+
+	target = filepath.Clean(target)
+	for path, entry := range index.Entries {
+		if target == "." || path == target || strings.HasPrefix(path, target+string(filepath.Separator)) {
+			e.notes[path] = exportNote{path: path, title: entry.Title}
+		}
+	}
+	if len(e.notes) == 0 {
+		return 0, fmt.Errorf("no notes found in %s", target)
+	}
+
+	for path := range e.notes {
+		if err := e.writeNote(path); err != nil {
+			return 0, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := e.writeIndex(cfg, skip); err != nil {
+		return 0, err
+	}
+	return len(e.notes), nil
+}
+
+func htmlPath(path string) string {
+	return strings.TrimSuffix(path, ".md") + ".html"
+}
+
+// relativeURL returns the link from the page for note from to the page for
+// note to, with each path segment escaped.
+func relativeURL(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(htmlPath(from)), htmlPath(to))
+	if err != nil {
+		rel = htmlPath(to)
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// headingID mirrors goldmark's automatic heading IDs.
+func headingID(heading string) string {
+	var id strings.Builder
+	for _, r := range strings.TrimSpace(heading) {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			id.WriteRune(r + 'a' - 'A')
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			id.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			id.WriteRune('-')
+		}
+	}
+	return id.String()
+}
+
+// rewriteLinks turns [[wikilinks]] into markdown links to exported pages and
+// points relative .md links at their .html counterparts.
+func (e *exporter) rewriteLinks(path, body string) string {
+	links := extractLinks(body)
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		raw := body[link.Start+2 : link.End-2]
+		text := raw
+		if j := strings.Index(raw, "|"); j >= 0 {
+			text = strings.TrimSpace(raw[j+1:])
+		}
+
+		var replacement string
+		resolved, ok := e.index.ResolveLink(link.Target, path)
+		if _, exported := e.notes[resolved]; ok && exported {
+			href := relativeURL(path, resolved)
+			if j := strings.Index(link.Target, "#"); j >= 0 {
+				href += "#" + headingID(link.Target[j+1:])
+			}
+			replacement = fmt.Sprintf("[%s](%s)", escapeLinkText(text), href)
+		} else {
+			replacement = fmt.Sprintf(`<a class="broken-link">%s</a>`, template.HTMLEscapeString(text))
+		}
+		body = body[:link.Start] + replacement + body[link.End:]
+	}
+
+	return mdLinkRe.ReplaceAllStringFunc(body, func(match string) string {
+		sub := mdLinkRe.FindStringSubmatch(match)
+		dest := sub[1]
+		anchor := ""
+		if i := strings.Index(dest, "#"); i >= 0 {
+			dest, anchor = dest[:i], dest[i:]
+		}
+		if !strings.HasSuffix(dest, ".md") || strings.Contains(dest, "://") {
+			return match
+		}
+		return strings.Replace(match, sub[1], strings.TrimSuffix(dest, ".md")+".html"+anchor, 1)
+	})
+}
+
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+}
+
+func (e *exporter) writeNote(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fm, body := parseFrontmatter(string(content))
+	body = e.rewriteLinks(path, body)
+
+	if err := e.copyImages(path, body); err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	if err := e.markdown.Convert([]byte(body), &rendered); err != nil {
+		return err
+	}
+
+	var properties []string
+	if !fm.Created.IsZero() {
+		properties = append(properties, "Created "+fm.Created.Format("2006-01-02"))
+	}
+	if !fm.Updated.IsZero() {
+		properties = append(properties, "Updated "+fm.Updated.Format("2006-01-02"))
+	}
+	if tags := extractTags(string(content)); len(tags) > 0 {
+		properties = append(properties, "#"+strings.Join(tags, " #"))
+	}
+
+	home, _ := filepath.Rel(filepath.Dir(path), "index.html")
+	return e.writePage(htmlPath(path), e.notes[path].title, filepath.ToSlash(home), strings.Join(properties, " • "), template.HTML(rendered.String()))
+}
+
+func (e *exporter) writePage(path, title, home, properties string, body template.HTML) error {
+	dest := filepath.Join(e.out, path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	var page bytes.Buffer
+	err := pageTemplate.Execute(&page, struct {
+		Title, Home, Properties string
+		Stylesheet              template.CSS
+		Body                    template.HTML
+	}{
+		Title:      title,
+		Home:       home,
+		Properties: properties,
+		Stylesheet: template.CSS(exportStylesheet),
+		Body:       body,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, page.Bytes(), 0644)
+}
+
+// copyImages copies local images referenced by the note next to its page so
+// that the relative references keep working.
+func (e *exporter) copyImages(path, body string) error {
+	for _, match := range imageRe.FindAllStringSubmatch(body, -1) {
+		src := match[1]
+		if strings.Contains(src, "://") || strings.HasPrefix(src, "data:") || strings.HasPrefix(src, "/") {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(src); err == nil {
+			src = unescaped
+		}
+		rel := filepath.Clean(filepath.Join(filepath.Dir(path), filepath.FromSlash(src)))
+		if strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if err := copyFile(rel, filepath.Join(e.out, rel)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeIndex writes index.html listing the exported notes as a tree ordered
+// like the sidebar.
+func (e *exporter) writeIndex(cfg *Config, skip func(string) bool) error {
+	tree := e.indexTree(cfg, skip, ".")
+	return e.writePage("index.html", "Notes", "index.html", "", template.HTML(tree))
+}
+
+// indexTree renders the exported notes below dir as nested lists, leaving out
+// folders without exported notes.
+func (e *exporter) indexTree(cfg *Config, skip func(string) bool, dir string) string {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var items []sortItem
+	for _, f := range files {
+		path := filepath.Join(dir, f.Name())
+		info, err := f.Info()
+		if err != nil || skip(path) {
+			continue
+		}
+		item := sortItem{name: f.Name(), modTime: info.ModTime(), created: info.ModTime()}
+		if f.IsDir() {
+			item.note = Note{path: path, title: f.Name(), isDir: true}
+		} else if note, ok := e.notes[path]; ok {
+			item.note = Note{path: path, title: note.title}
+			if created := e.index.Created(path); !created.IsZero() {
+				item.created = created
+			}
+		} else {
+			continue
+		}
+		items = append(items, item)
+	}
+	sortItems(items, cfg.SortOrderFor(dir))
+
+	var list strings.Builder
+	for _, item := range items {
+		title := template.HTMLEscapeString(item.note.title)
+		if item.note.isDir {
+			if inner := e.indexTree(cfg, skip, item.note.path); inner != "" {
+				fmt.Fprintf(&list, "<li><span class=\"folder\">%s</span>\n%s</li>\n", title, inner)
+			}
+			continue
+		}
+		fmt.Fprintf(&list, "<li><a href=\"%s\">%s</a></li>\n", relativeURL("index.md", item.note.path), title)
+	}
+	if list.Len() == 0 {
+		return ""
+	}
+	return "<ul class=\"tree\">\n" + list.String() + "</ul>\n"
+}
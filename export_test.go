@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportHTML(t *testing.T) {
+	root := t.TempDir()
+	out := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"inbox.md":                 "---\ntitle: Inbox\n---\nSee [[Project Alpha|alpha]], [[alpha#Next Steps]], [[Missing]] and [doc](projects/alpha.md).\n",
+		"projects/alpha.md":        "# Project Alpha\n![diagram](img/diagram.png)\n## Next Steps\n",
+		"projects/img/diagram.png": "png",
+	})
+
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	skip := func(string) bool { return false }
+	idx.Refresh(".", skip)
+
+	count, err := exportHTML(idx, DefaultConfig(), ".", out, skip)
+	if err != nil {
+		t.Fatalf("exportHTML() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("exportHTML() exported %d notes, want 2", count)
+	}
+
+	inbox, err := os.ReadFile(filepath.Join(out, "inbox.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<title>Inbox</title>`,
+		`<a href="projects/alpha.html">alpha</a>`,
+		`<a href="projects/alpha.html#next-steps">alpha#Next Steps</a>`,
+		`<a class="broken-link">Missing</a>`,
+		`<a href="projects/alpha.html">doc</a>`,
+	} {
+		if !strings.Contains(string(inbox), want) {
+			t.Errorf("inbox.html is missing %s", want)
+		}
+	}
+	if strings.Contains(string(inbox), "title: Inbox") {
+		t.Errorf("inbox.html still contains the frontmatter")
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "projects", "img", "diagram.png")); err != nil {
+		t.Errorf("referenced image was not copied: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<span class="folder">projects</span>`) || !strings.Contains(string(index), `href="projects/alpha.html"`) {
+		t.Errorf("index.html does not mirror the note tree:\n%s", index)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// splitFrontmatter separates a leading "---" delimited YAML block from the
+// rest of the note.
+func splitFrontmatter(content string) (frontmatter, body string, ok bool) {
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content, false
+	}
+	rest := content[strings.Index(content, "\n")+1:]
+	for offset := 0; offset < len(rest); {
+		end := strings.Index(rest[offset:], "\n")
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if strings.TrimRight(line, "\r") == "---" {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], body, true
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	return "", content, false
+}
+
+// Field is a frontmatter property without dedicated handling, formatted for
+// display.
+type Field struct {
+	Key, Value string
+}
+
+type Frontmatter struct {
+	Title   string
+	Aliases []string
+	Tags    []string
+	Created time.Time
+	Updated time.Time
+	ID      string
+	Fields  []Field // custom properties in document order
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseFrontmatter decodes the YAML frontmatter of a note. Malformed YAML is
+// treated as if the note had no frontmatter.
+func parseFrontmatter(content string) (Frontmatter, string) {
+	var fm Frontmatter
+	raw, body, ok := splitFrontmatter(content)
+	if !ok {
+		return fm, content
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fm, body
+	}
+
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i].Value, mapping.Content[i+1]
+		switch strings.ToLower(key) {
+		case "title":
+			fm.Title = strings.TrimSpace(value.Value)
+		case "aliases", "alias":
+			fm.Aliases = nodeList(value)
+		case "tags", "tag":
+			fm.Tags = nodeList(value)
+		case "id":
+			fm.ID = value.Value
+		case "created", "date":
+			if t, ok := parseDate(value.Value); ok {
+				fm.Created = t
+			} else {
+				fm.Fields = append(fm.Fields, Field{Key: key, Value: formatNode(value)})
+			}
+		case "updated", "modified":
+			if t, ok := parseDate(value.Value); ok {
+				fm.Updated = t
+			} else {
+				fm.Fields = append(fm.Fields, Field{Key: key, Value: formatNode(value)})
+			}
+		default:
+			fm.Fields = append(fm.Fields, Field{Key: key, Value: formatNode(value)})
+		}
+	}
+	return fm, body
+}
+
+// nodeList reads a YAML sequence, or a comma or space separated string.
+func nodeList(node *yaml.Node) []string {
+	var items []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Value != "" {
+				items = append(items, item.Value)
+			}
+		}
+	case yaml.ScalarNode:
+		items = strings.FieldsFunc(node.Value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+	return items
+}
+
+func formatNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			items = append(items, formatNode(item))
+		}
+		return strings.Join(items, ", ")
+	}
+	node.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// createdLineRe matches the "Created:" line createNote writes below the title.
+var createdLineRe = regexp.MustCompile(`(?m)^Created: (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\r?$`)
+
+// noteCreated returns when a note was created: its frontmatter date or, for
+// notes made by createNote, the time on their "Created:" line.
+func noteCreated(fm Frontmatter, body string) time.Time {
+	if !fm.Created.IsZero() {
+		return fm.Created
+	}
+	if match := createdLineRe.FindStringSubmatch(body); match != nil {
+		if t, ok := parseDate(match[1]); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// renderProperties renders frontmatter as a compact header above the preview.
+func (m Model) renderProperties(fm Frontmatter) string {
+	var parts []string
+	if !fm.Created.IsZero() {
+		parts = append(parts, "created "+fm.Created.Format("2006-01-02"))
+	}
+	if !fm.Updated.IsZero() {
+		parts = append(parts, "updated "+fm.Updated.Format("2006-01-02"))
+	}
+	if fm.ID != "" {
+		parts = append(parts, "id "+fm.ID)
+	}
+	if len(fm.Aliases) > 0 {
+		parts = append(parts, "aka "+strings.Join(fm.Aliases, ", "))
+	}
+	if len(fm.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(fm.Tags, " #"))
+	}
+	for _, field := range fm.Fields {
+		parts = append(parts, field.Key+": "+field.Value)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return m.styles.muted.
+		Width(m.viewport.Width-4).
+		Padding(0, 2).
+		Render(strings.Join(parts, " • ")) + "\n"
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	content := "---\ntitle: Real Title\naliases: [First, Second]\ncreated: 2024-03-01\nupdated: 2024-03-02 10:30\nid: abc123\nstatus: draft\nowners:\n  - ann\n  - bob\n---\n# Heading\nBody\n"
+	fm, body := parseFrontmatter(content)
+
+	if fm.Title != "Real Title" || fm.ID != "abc123" {
+		t.Errorf("title/id = %q/%q", fm.Title, fm.ID)
+	}
+	if !reflect.DeepEqual(fm.Aliases, []string{"First", "Second"}) {
+		t.Errorf("aliases = %v", fm.Aliases)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local); !fm.Created.Equal(want) {
+		t.Errorf("created = %v, want %v", fm.Created, want)
+	}
+	if want := time.Date(2024, 3, 2, 10, 30, 0, 0, time.Local); !fm.Updated.Equal(want) {
+		t.Errorf("updated = %v, want %v", fm.Updated, want)
+	}
+	wantFields := []Field{{Key: "status", Value: "draft"}, {Key: "owners", Value: "ann, bob"}}
+	if !reflect.DeepEqual(fm.Fields, wantFields) {
+		t.Errorf("fields = %v, want %v", fm.Fields, wantFields)
+	}
+	if body != "# Heading\nBody\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestParseFrontmatterWithoutBlock(t *testing.T) {
+	content := "# Just a note\n---\nnot: frontmatter\n"
+	fm, body := parseFrontmatter(content)
+	if !reflect.DeepEqual(fm, Frontmatter{}) || body != content {
+		t.Errorf("parseFrontmatter() = %+v, %q", fm, body)
+	}
+}
+
+func TestNoteTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "frontmatter title wins", content: "---\ntitle: From YAML\n---\n# From heading\n", expected: "From YAML"},
+		{name: "heading after frontmatter", content: "---\n# yaml comment\ntags: [a]\n---\n# From heading\n", expected: "From heading"},
+		{name: "file name fallback", content: "no heading", expected: "my-note"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noteTitle(filepath.Join("dir", "my-note.md"), tt.content); got != tt.expected {
+				t.Errorf("noteTitle() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveLinkByAlias(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		"meeting.md": "---\naliases: [Standup]\n---\n# Daily meeting\n",
+	})
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(root, func(string) bool { return false })
+
+	got, ok := idx.ResolveLink("standup", filepath.Join(root, "other.md"))
+	if !ok || got != filepath.Join(root, "meeting.md") {
+		t.Errorf("ResolveLink(standup) = %v, %v", got, ok)
+	}
+}
+
+func TestCreatedFromNewNote(t *testing.T) {
+	root := t.TempDir()
+	path, err := createNote(root, "Fresh", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, time.Now(), time.Date(2001, 1, 1, 0, 0, 0, 0, time.Local)); err != nil {
+		t.Fatal(err)
+	}
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(root, func(string) bool { return false })
+
+	if got := idx.Created(path); got.IsZero() || got.Year() < 2024 {
+		t.Errorf("Created(new note) = %v, want the time on its Created line", got)
+	}
+
+	fm, body := parseFrontmatter("# Old\n\nCreated: 2023-05-06 07:08:09\n")
+	if want := time.Date(2023, 5, 6, 7, 8, 9, 0, time.Local); !noteCreated(fm, body).Equal(want) {
+		t.Errorf("noteCreated() = %v, want %v", noteCreated(fm, body), want)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/gob"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// indexVersion is bumped whenever the on-disk layout changes so that stale
+// index files are rebuilt instead of decoded into the wrong shape.
+const indexVersion = 6
+
+type IndexEntry struct {
+	Title   string
+	ModTime int64
+	Size    int64
+	Terms   []string
+	Links   []string // wikilink targets as written in the note
+	Tags    []string
+	Aliases []string
+	Created int64 // creation time from the note itself, 0 if unknown
+}
+
+// Index is an inverted index over every note in the vault. It is persisted
+// under the config directory and only re-reads files whose mtime or size
+// changed since they were last indexed.
+type Index struct {
+	Version  int
+	Root     string
+	Entries  map[string]*IndexEntry
+	Postings map[string]map[string]int // term -> note path -> occurrences
+
+	file  string
+	dirty bool
+
+	// Lookup tables for link resolution, rebuilt lazily after changes
+	names, titles, aliases map[string][]string
+	// linkedFrom maps a note to the notes linking to it. Adding or renaming
+	// a note can change what links resolve to, so it is rebuilt along with
+	// the lookup tables rather than patched.
+	linkedFrom map[string][]string
+}
+
+func newIndex(file, root string) *Index {
+	return &Index{
+		Version:  indexVersion,
+		Root:     root,
+		Entries:  make(map[string]*IndexEntry),
+		Postings: make(map[string]map[string]int),
+		file:     file,
+	}
+}
+
+// OpenIndex loads the index stored in file. A missing, corrupt or outdated
+// index, or one built for a different notes directory, yields an empty index.
+func OpenIndex(file, root string) *Index {
+	f, err := os.Open(file)
+	if err != nil {
+		return newIndex(file, root)
+	}
+	defer f.Close()
+
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil || idx.Version != indexVersion || idx.Root != root {
+		return newIndex(file, root)
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]*IndexEntry)
+	}
+	if idx.Postings == nil {
+		idx.Postings = make(map[string]map[string]int)
+	}
+	idx.file = file
+	return idx
+}
+
+// Save writes the index to disk if it changed since it was loaded.
+func (idx *Index) Save() error {
+	if !idx.dirty {
+		return nil
+	}
+
+	tmp := idx.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(idx); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, idx.file); err != nil {
+		return err
+	}
+	idx.dirty = false
+	return nil
+}
+
+// Update re-indexes path if its mtime or size differ from the indexed entry.
+func (idx *Index) Update(path string, info fs.FileInfo) *IndexEntry {
+	entry, ok := idx.Entries[path]
+	if ok && entry.ModTime == info.ModTime().UnixNano() && entry.Size == info.Size() {
+		return entry
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		idx.Remove(path)
+		return nil
+	}
+
+	idx.Remove(path)
+	counts := make(map[string]int)
+	for _, term := range tokenize(string(content)) {
+		counts[term]++
+	}
+
+	entry = &IndexEntry{
+		Title:   noteTitle(path, string(content)),
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+	}
+	entry.Tags = extractTags(string(content))
+	fm, body := parseFrontmatter(string(content))
+	entry.Aliases = fm.Aliases
+	if created := noteCreated(fm, body); !created.IsZero() {
+		entry.Created = created.UnixNano()
+	}
+	for _, link := range extractLinks(string(content)) {
+		entry.Links = append(entry.Links, link.Target)
+	}
+	for term, count := range counts {
+		entry.Terms = append(entry.Terms, term)
+		if idx.Postings[term] == nil {
+			idx.Postings[term] = make(map[string]int)
+		}
+		idx.Postings[term][path] = count
+	}
+	idx.Entries[path] = entry
+	idx.dirty = true
+	idx.names, idx.titles, idx.aliases, idx.linkedFrom = nil, nil, nil, nil
+	return entry
+}
+
+func (idx *Index) Remove(path string) {
+	entry, ok := idx.Entries[path]
+	if !ok {
+		return
+	}
+	for _, term := range entry.Terms {
+		delete(idx.Postings[term], path)
+		if len(idx.Postings[term]) == 0 {
+			delete(idx.Postings, term)
+		}
+	}
+	delete(idx.Entries, path)
+	idx.dirty = true
+	idx.names, idx.titles, idx.aliases, idx.linkedFrom = nil, nil, nil, nil
+}
+
+// Refresh brings the index in line with the notes below root, re-reading only
+// changed files and dropping entries for notes that no longer exist.
+func (idx *Index) Refresh(root string, skip func(path string) bool) {
+	seen := make(map[string]bool)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && skip(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if idx.Update(path, info) != nil {
+			seen[path] = true
+		}
+		return nil
+	})
+
+	for path := range idx.Entries {
+		if !seen[path] {
+			idx.Remove(path)
+		}
+	}
+}
+
+// Title returns the indexed title of path, refreshing the entry if needed.
+func (idx *Index) Title(path string, info fs.FileInfo) string {
+	if entry := idx.Update(path, info); entry != nil {
+		return entry.Title
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".md")
+}
+
+// Created returns the creation time recorded in path, if it has one.
+func (idx *Index) Created(path string) time.Time {
+	if entry, ok := idx.Entries[path]; ok && entry.Created != 0 {
+		return time.Unix(0, entry.Created)
+	}
+	return time.Time{}
+}
+
+// Tags returns the indexed tags of path.
+func (idx *Index) Tags(path string) []string {
+	if entry, ok := idx.Entries[path]; ok {
+		return entry.Tags
+	}
+	return nil
+}
+
+// noteTitle prefers a frontmatter title, then the first heading and finally
+// the file name.
+func noteTitle(path, content string) string {
+	fm, body := parseFrontmatter(content)
+	if fm.Title != "" {
+		return fm.Title
+	}
+	if title := extractTitle(body); title != "" {
+		return title
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".md")
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
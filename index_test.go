@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexUpdateSkipsUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.md")
+	writeNotes(t, root, map[string]string{"a.md": "# First\n"})
+	stamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(path, stamp, stamp)
+
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	info, _ := os.Stat(path)
+	if got := idx.Title(path, info); got != "First" {
+		t.Fatalf("Title() = %v, want First", got)
+	}
+
+	// Same size and mtime: the file must not be read again
+	os.WriteFile(path, []byte("# Other\n"), 0644)
+	os.Chtimes(path, stamp, stamp)
+	info, _ = os.Stat(path)
+	if got := idx.Title(path, info); got != "First" {
+		t.Errorf("Title() = %v, want cached First", got)
+	}
+
+	os.WriteFile(path, []byte("# Changed title\n"), 0644)
+	info, _ = os.Stat(path)
+	if got := idx.Title(path, info); got != "Changed title" {
+		t.Errorf("Title() = %v, want Changed title", got)
+	}
+	if _, ok := idx.Postings["first"]; ok {
+		t.Errorf("stale term still present in postings")
+	}
+}
+
+func TestIndexSaveAndOpen(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(t.TempDir(), "index.gob")
+	writeNotes(t, root, map[string]string{"a.md": "# Saved\nhello", "b.md": "bye"})
+
+	idx := newIndex(file, root)
+	idx.Refresh(root, func(string) bool { return false })
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := OpenIndex(file, root)
+	if len(loaded.Entries) != 2 || loaded.Postings["hello"][filepath.Join(root, "a.md")] != 1 {
+		t.Errorf("OpenIndex() did not restore entries: %+v", loaded.Entries)
+	}
+
+	if other := OpenIndex(file, "/elsewhere"); len(other.Entries) != 0 {
+		t.Errorf("OpenIndex() reused an index built for another root")
+	}
+
+	os.Remove(filepath.Join(root, "b.md"))
+	loaded.Refresh(root, func(string) bool { return false })
+	if _, ok := loaded.Entries[filepath.Join(root, "b.md")]; ok {
+		t.Errorf("Refresh() kept an entry for a deleted note")
+	}
+	if _, ok := loaded.Postings["bye"]; ok {
+		t.Errorf("Refresh() kept postings for a deleted note")
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// listItemRe matches the marker of a markdown list item, with its indent,
+// bullet or number, spacing and optional task checkbox.
+var listItemRe = regexp.MustCompile(`^(\s*)([-*+]|(\d+)([.)]))(\s+)(\[[ xX]\]\s+)?`)
+
+// checkboxRe matches a list item's task checkbox, capturing its mark.
+var checkboxRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]`)
+
+// continueList returns the marker that continues the list item on line: the
+// same bullet, the next number or an unchecked box. done reports an empty
+// item, which ends the list instead.
+func continueList(line string) (next string, done bool) {
+	match := listItemRe.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	if strings.TrimSpace(line[len(match[0]):]) == "" {
+		return "", true
+	}
+
+	marker := match[2]
+	if match[3] != "" {
+		n, _ := strconv.Atoi(match[3])
+		marker = strconv.Itoa(n+1) + match[4]
+	}
+	next = match[1] + marker + match[5]
+	if match[6] != "" {
+		next += "[ ] "
+	}
+	return next, false
+}
+
+// toggleCheckbox checks or unchecks the task on line, or turns a plain list
+// item into an unchecked task. It reports false for lines that are not list
+// items.
+func toggleCheckbox(line string) (string, bool) {
+	if loc := checkboxRe.FindStringSubmatchIndex(line); loc != nil {
+		mark := "x"
+		if line[loc[2]:loc[3]] != " " {
+			mark = " "
+		}
+		return line[:loc[2]] + mark + line[loc[3]:], true
+	}
+	if marker := listItemRe.FindString(line); marker != "" {
+		return marker + "[ ] " + line[len(marker):], true
+	}
+	return line, false
+}
+
+// startInlineEdit opens the selected note in the textarea of the content
+// pane, with the cursor on the highlighted link or paragraph.
+func (m *Model) startInlineEdit() tea.Cmd {
+	path := m.notes[m.cursor].path
+	content, err := os.ReadFile(path)
+	if err != nil {
+		m.notifyError("Could not read %s: %v", path, err)
+		return nil
+	}
+
+	m.editor = textarea.New()
+	m.editor.ShowLineNumbers = false
+	m.editor.CharLimit = 0
+	m.editor.MaxHeight = 0
+	m.editor.MaxWidth = 0
+	m.editor.SetValue(string(content))
+	m.moveEditorCursor(m.editLine()-1, 0)
+
+	m.editing = true
+	m.editPath = path
+	m.editSaved = string(content)
+	m.editRendered = ""
+	m.confirmDiscard = false
+	m.confirmQuit = false
+	m.resizeEditor()
+	m.refreshEditPreview()
+	return m.editor.Focus()
+}
+
+func (m Model) updateInlineEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Keys that type something are text; the others, like ctrl+c, are actions
+	action := m.keys.inInput(panelEditor, msg)
+	quit := action == actionQuit
+	if m.confirmQuit {
+		m.confirmQuit = false
+		if quit {
+			return m, m.quit()
+		}
+		return m, nil
+	}
+	if quit {
+		if !m.editDirty() {
+			return m, m.quit()
+		}
+		m.confirmQuit = true
+		return m, nil
+	}
+
+	if m.confirmDiscard {
+		m.confirmDiscard = false
+		if action == actionClear {
+			m.notify("Discarded changes to %s", m.editPath)
+			m.closeInlineEdit()
+		}
+		return m, nil
+	}
+
+	switch action {
+	case actionSave:
+		m.saveInlineEdit()
+		return m, nil
+	case actionClear:
+		if m.editDirty() {
+			m.confirmDiscard = true
+		} else {
+			m.closeInlineEdit()
+		}
+		return m, nil
+	case actionLivePreview:
+		m.editPreview = !m.editPreview
+		m.resizeEditor()
+		m.refreshEditPreview()
+		return m, nil
+	case actionToggleCheckbox:
+		line, col := m.editorLine()
+		if toggled, ok := toggleCheckbox(line); ok {
+			m.setEditorLine(toggled, col+len([]rune(toggled))-len([]rune(line)))
+			m.refreshEditPreview()
+		}
+		return m, nil
+	}
+	if msg.Type == tea.KeyEnter {
+		line, col := m.editorLine()
+		if marker := listItemRe.FindString(line); marker != "" && col >= len([]rune(marker)) {
+			if next, done := continueList(line); done {
+				m.setEditorLine("", 0)
+			} else {
+				m.editor.InsertString("\n" + next)
+			}
+			m.refreshEditPreview()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	m.refreshEditPreview()
+	return m, cmd
+}
+
+func (m Model) editDirty() bool {
+	return m.editor.Value() != m.editSaved
+}
+
+func (m *Model) saveInlineEdit() {
+	value := m.editor.Value()
+	if err := writeFileAtomic(m.editPath, []byte(value)); err != nil {
+		m.notifyError("Could not save %s: %v", m.editPath, err)
+		return
+	}
+	m.editSaved = value
+	m.index.Refresh(".", m.isArchiveDir)
+	m.saveIndex()
+	m.notify("Saved %s", m.editPath)
+}
+
+// closeInlineEdit leaves the editor, dropping unsaved changes, and reloads
+// the note as if an external editor had exited.
+func (m *Model) closeInlineEdit() {
+	m.editing = false
+	m.editor.Blur()
+	if m.editPreview {
+		m.setRendererWidth(m.viewport.Width - 4)
+	}
+	m.editorFinished(editorFinishedMsg{path: m.editPath})
+}
+
+// editorLine returns the line the editor cursor is on and the cursor's
+// column in runes.
+func (m Model) editorLine() (string, int) {
+	lines := strings.Split(m.editor.Value(), "\n")
+	info := m.editor.LineInfo()
+	return lines[m.editor.Line()], info.StartColumn + info.ColumnOffset
+}
+
+// setEditorLine replaces the line the cursor is on and puts the cursor at
+// col. The textarea can only edit at the cursor, so the whole value is reset.
+func (m *Model) setEditorLine(text string, col int) {
+	row := m.editor.Line()
+	lines := strings.Split(m.editor.Value(), "\n")
+	lines[row] = text
+	m.editor.SetValue(strings.Join(lines, "\n"))
+	m.moveEditorCursor(row, col)
+}
+
+// moveEditorCursor moves the cursor to row and col. SetValue leaves it at
+// the end of the text, so it only ever needs to move up.
+func (m *Model) moveEditorCursor(row, col int) {
+	if row < 0 {
+		row = 0
+	}
+	for m.editor.Line() > row {
+		m.editor.CursorUp()
+	}
+	m.editor.SetCursor(col)
+}
+
+// editorWidths splits the content pane between the textarea and the live
+// preview, which is drawn with a one column border.
+func (m Model) editorWidths() (editor, preview int) {
+	if !m.editPreview {
+		return m.viewport.Width, 0
+	}
+	editor = m.viewport.Width / 2
+	return editor, m.viewport.Width - editor - 1
+}
+
+// resizeEditor fits the textarea to the content pane and rewraps the
+// markdown renderer to the width of the preview.
+func (m *Model) resizeEditor() {
+	editorWidth, previewWidth := m.editorWidths()
+	m.editor.SetWidth(editorWidth)
+	m.editor.SetHeight(m.viewport.Height)
+	if m.editPreview {
+		m.setRendererWidth(previewWidth - 5)
+	} else {
+		m.setRendererWidth(m.viewport.Width - 4)
+	}
+}
+
+func (m *Model) setRendererWidth(width int) {
+	if m.mdRenderer == nil {
+		return
+	}
+	renderer, err := newMarkdownRenderer(m.theme, width)
+	if err != nil {
+		m.notifyError("Could not load glamour style %s: %v", m.theme.GlamourStyle, err)
+		return
+	}
+	m.mdRenderer = renderer
+}
+
+// refreshEditPreview queues a render of the editor contents for the live
+// preview. Until the first one is done the preview shows the source.
+func (m *Model) refreshEditPreview() {
+	if !m.editPreview {
+		return
+	}
+	meta, body := parseFrontmatter(m.editor.Value())
+	if m.previews == nil {
+		m.editRendered = m.renderProperties(meta) + m.renderMarkdown(body)
+		return
+	}
+	if m.editRendered == "" {
+		_, width := m.editorWidths()
+		m.editRendered = ansi.Wrap(m.editor.Value(), width-5, "")
+	}
+	m.pendingRender = &renderJob{key: renderKey{path: m.editPath}, body: body, edit: true}
+}
+
+// renderInlineEditor draws the textarea and, when enabled, the preview
+// scrolled to about the same place in the note as the cursor.
+func (m Model) renderInlineEditor() string {
+	editor := m.editor.View()
+	if !m.editPreview {
+		return editor
+	}
+
+	_, previewWidth := m.editorWidths()
+	height := m.viewport.Height
+	lines := strings.Split(m.editRendered, "\n")
+	offset := m.editor.Line()*len(lines)/m.editor.LineCount() - height/2
+	if offset > len(lines)-height {
+		offset = len(lines) - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	preview := lipgloss.NewStyle().
+		Width(previewWidth).
+		Height(height).
+		MaxHeight(height).
+		PaddingLeft(1).
+		BorderStyle(m.styles.border).
+		BorderLeft(true).
+		BorderForeground(m.styles.highlight).
+		Render(strings.Join(lines[offset:end], "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, editor, preview)
+}
+
+func (m Model) inlineEditorStatus() string {
+	if m.confirmDiscard {
+		return fmt.Sprintf("Discard unsaved changes to %s? %s: discard • any other key: keep editing",
+			m.editPath, keyLabel(inputBindings(m.config.Keys)[actionClear]))
+	}
+	if m.confirmQuit {
+		return fmt.Sprintf("%s has unsaved changes: quit again to quit without saving • any other key: keep editing", m.editPath)
+	}
+	if m.editDirty() {
+		return m.editPath + " • ● modified"
+	}
+	return m.editPath
+}
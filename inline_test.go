@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestContinueList(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantNext string
+		wantDone bool
+	}{
+		{"- milk", "- ", false},
+		{"  * nested", "  * ", false},
+		{"9. ninth", "10. ", false},
+		{"1) first", "2) ", false},
+		{"- [x] done", "- [ ] ", false},
+		{"- ", "", true},
+		{"3. [ ] ", "", true},
+		{"plain text", "", false},
+		{"**bold**", "", false},
+	}
+
+	for _, tt := range tests {
+		next, done := continueList(tt.line)
+		if next != tt.wantNext || done != tt.wantDone {
+			t.Errorf("continueList(%q) = %q, %v, want %q, %v", tt.line, next, done, tt.wantNext, tt.wantDone)
+		}
+	}
+}
+
+func TestToggleCheckbox(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"- [ ] buy milk", "- [x] buy milk", true},
+		{"  1. [X] call", "  1. [ ] call", true},
+		{"- buy milk", "- [ ] buy milk", true},
+		{"buy milk", "buy milk", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := toggleCheckbox(tt.line)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("toggleCheckbox(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInlineEditor(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{"todo.md": "# Todo\n\n- [ ] milk"})
+	keys, _ := NewKeyMap(DefaultKeys())
+	m := Model{
+		config:   DefaultConfig(),
+		keys:     keys,
+		index:    newIndex(filepath.Join(t.TempDir(), "index.gob"), root),
+		journal:  &Journal{},
+		viewport: viewport.New(80, 20),
+	}
+	m.updateNotes()
+
+	press := func(keys ...string) {
+		t.Helper()
+		for _, key := range keys {
+			var msg tea.KeyMsg
+			switch key {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			case "ctrl+s":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlS}
+			case "ctrl+t":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlT}
+			case "ctrl+o":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlO}
+			case "ctrl+c":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlC}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			}
+			model, _ := m.Update(msg)
+			m = model.(Model)
+		}
+	}
+
+	press("e")
+	if !m.editing {
+		t.Fatalf("e should open the inline editor")
+	}
+	m.editor.CursorDown()
+	m.editor.CursorDown()
+	m.editor.CursorEnd()
+	press("enter", "e", "g", "g", "s", "ctrl+t")
+	if got := m.editor.Value(); got != "# Todo\n\n- [ ] milk\n- [x] eggs" {
+		t.Errorf("editor value = %q", got)
+	}
+	if !m.editDirty() {
+		t.Errorf("edited note should be dirty")
+	}
+
+	// Leaving with unsaved changes asks first; any other key keeps editing
+	press("esc", "x")
+	if !m.editing || m.confirmDiscard {
+		t.Errorf("editor should stay open after declining to discard")
+	}
+
+	press("ctrl+o")
+	if !strings.Contains(m.editRendered, "eggs") || !strings.Contains(m.renderInlineEditor(), "eggs") {
+		t.Errorf("live preview = %q", m.editRendered)
+	}
+
+	press("ctrl+s")
+	data, _ := os.ReadFile("todo.md")
+	if string(data) != "# Todo\n\n- [ ] milk\n- [x] eggs" || m.editDirty() {
+		t.Errorf("ctrl+s saved %q, dirty = %v", data, m.editDirty())
+	}
+
+	press("enter", "enter", "esc")
+	if !m.confirmDiscard {
+		t.Fatalf("esc with unsaved changes should ask for confirmation")
+	}
+	press("esc")
+	data, _ = os.ReadFile("todo.md")
+	if m.editing || string(data) != "# Todo\n\n- [ ] milk\n- [x] eggs" {
+		t.Errorf("second esc should discard the changes, file = %q", data)
+	}
+
+	// ctrl+c quits from the editor, asking first when there are changes,
+	// while q is typed
+	m.config.ConfigDir = t.TempDir()
+	press("e", "q", "ctrl+c")
+	if !m.editing || !m.confirmQuit || !strings.Contains(m.editor.Value(), "q") {
+		t.Fatalf("ctrl+c with unsaved changes should ask first, value = %q", m.editor.Value())
+	}
+	value := m.editor.Value()
+	press("x")
+	if m.confirmQuit || m.editor.Value() != value {
+		t.Errorf("any other key should keep editing")
+	}
+	press("ctrl+c")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatalf("confirming should quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("confirming should quit")
+	}
+}
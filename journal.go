@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// maxJournal caps how many operations can be undone.
+const maxJournal = 100
+
+type opKind string
+
+const (
+	opCreateNote   opKind = "create_note"
+	opCreateFolder opKind = "create_folder"
+	opRename       opKind = "rename"
+	opArchive      opKind = "archive"
+	opRestore      opKind = "restore"
+)
+
+// Operation is a file operation made from the TUI, recorded so that it can
+// be undone and redone.
+type Operation struct {
+	Kind    opKind    `yaml:"kind"`
+	Path    string    `yaml:"path"`              // note or folder created, renamed, archived or restored
+	To      string    `yaml:"to,omitempty"`      // new path of a rename
+	Links   bool      `yaml:"links,omitempty"`   // the rename rewrote links
+	Archive string    `yaml:"archive,omitempty"` // name in the archive dir
+	Content string    `yaml:"content,omitempty"` // contents of a created note
+	Session string    `yaml:"session"`
+	Time    time.Time `yaml:"time"`
+}
+
+// Journal is the undo and redo history of file operations. It is persisted
+// under the config directory so operations can be undone after a restart.
+type Journal struct {
+	Root   string      `yaml:"root"`
+	Done   []Operation `yaml:"done"`
+	Undone []Operation `yaml:"undone"`
+
+	file    string
+	session string
+}
+
+// OpenJournal loads the journal stored in file. A missing or unreadable
+// journal, or one kept for a different notes directory, starts empty.
+func OpenJournal(file, root string) *Journal {
+	j := &Journal{}
+	data, err := os.ReadFile(file)
+	if err != nil || yaml.Unmarshal(data, j) != nil || j.Root != root {
+		j = &Journal{Root: root}
+	}
+	j.file = file
+	j.session = time.Now().Format("2006-01-02T15:04:05")
+	return j
+}
+
+func (j *Journal) save() error {
+	data, err := yaml.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(j.file, data)
+}
+
+// Record adds an operation that was just made, dropping the redo history.
+func (j *Journal) Record(op Operation) error {
+	op.Session = j.session
+	op.Time = time.Now()
+	j.Done = append(j.Done, op)
+	if len(j.Done) > maxJournal {
+		j.Done = j.Done[len(j.Done)-maxJournal:]
+	}
+	j.Undone = nil
+	return j.save()
+}
+
+// Undo reverses the last operation and returns it.
+func (j *Journal) Undo(cfg *Config, idx *Index) (Operation, error) {
+	if len(j.Done) == 0 {
+		return Operation{}, fmt.Errorf("nothing to undo")
+	}
+	op := j.Done[len(j.Done)-1]
+	if err := op.undo(cfg, idx); err != nil {
+		return op, err
+	}
+	j.Done = j.Done[:len(j.Done)-1]
+	j.Undone = append(j.Undone, op)
+	return op, j.save()
+}
+
+// Redo repeats the last undone operation and returns it.
+func (j *Journal) Redo(cfg *Config, idx *Index) (Operation, error) {
+	if len(j.Undone) == 0 {
+		return Operation{}, fmt.Errorf("nothing to redo")
+	}
+	op := j.Undone[len(j.Undone)-1]
+	if err := op.redo(cfg, idx); err != nil {
+		return op, err
+	}
+	j.Undone = j.Undone[:len(j.Undone)-1]
+	j.Done = append(j.Done, op)
+	return op, j.save()
+}
+
+func (op *Operation) undo(cfg *Config, idx *Index) error {
+	switch op.Kind {
+	case opCreateNote:
+		content, err := os.ReadFile(op.Path)
+		if err != nil {
+			return err
+		}
+		if string(content) != op.Content {
+			return fmt.Errorf("%s was edited since it was created", op.Path)
+		}
+		return os.Remove(op.Path)
+	case opCreateFolder:
+		// Only an empty folder is removed
+		return os.Remove(op.Path)
+	case opRename:
+		return idx.planRename(op.To, op.Path).Apply(op.Links)
+	case opArchive:
+		return restoreArchived(cfg, ArchiveEntry{Name: op.Archive}, op.Path)
+	case opRestore:
+		return op.archive(cfg)
+	}
+	return fmt.Errorf("unknown operation %q", op.Kind)
+}
+
+func (op *Operation) redo(cfg *Config, idx *Index) error {
+	switch op.Kind {
+	case opCreateNote:
+		if _, err := os.Lstat(op.Path); err == nil {
+			return fmt.Errorf("%s already exists", op.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(op.Path), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(op.Path, []byte(op.Content), 0644)
+	case opCreateFolder:
+		return os.Mkdir(op.Path, 0755)
+	case opRename:
+		return idx.planRename(op.Path, op.To).Apply(op.Links)
+	case opArchive:
+		return op.archive(cfg)
+	case opRestore:
+		return restoreArchived(cfg, ArchiveEntry{Name: op.Archive}, op.Path)
+	}
+	return fmt.Errorf("unknown operation %q", op.Kind)
+}
+
+// archive moves op.Path to the archive again, remembering its new name.
+func (op *Operation) archive(cfg *Config) error {
+	archivePath, err := archiveNote(cfg, op.Path)
+	if archivePath != "" {
+		op.Archive = filepath.Base(archivePath)
+	}
+	return err
+}
+
+func (op Operation) String() string {
+	switch op.Kind {
+	case opCreateNote:
+		return "creation of " + op.Path
+	case opCreateFolder:
+		return "creation of " + op.Path + "/"
+	case opRename:
+		return "rename of " + op.Path + " to " + op.To
+	case opArchive:
+		return "archiving of " + op.Path
+	case opRestore:
+		return "restore of " + op.Path
+	}
+	return string(op.Kind)
+}
+
+// target is the path an operation leaves selected once applied or undone.
+func (op Operation) target(undone bool) string {
+	if op.Kind == opRename && !undone {
+		return op.To
+	}
+	return op.Path
+}
+
+func (m *Model) record(op Operation) {
+	if err := m.journal.Record(op); err != nil {
+		m.notifyError("Could not save the undo journal: %v", err)
+	}
+}
+
+func (m *Model) undo() {
+	if len(m.journal.Done) == 0 {
+		m.notify("Nothing to undo")
+		return
+	}
+	m.index.Refresh(".", m.isArchiveDir)
+	op, err := m.journal.Undo(m.config, m.index)
+	if err != nil {
+		m.notifyError("Could not undo the %s: %v", op, err)
+		return
+	}
+	m.notify("Undid the %s", op)
+	m.afterJournal(op.target(true))
+}
+
+func (m *Model) redo() {
+	if len(m.journal.Undone) == 0 {
+		m.notify("Nothing to redo")
+		return
+	}
+	m.index.Refresh(".", m.isArchiveDir)
+	op, err := m.journal.Redo(m.config, m.index)
+	if err != nil {
+		m.notifyError("Could not redo the %s: %v", op, err)
+		return
+	}
+	m.notify("Redid the %s", op)
+	m.afterJournal(op.target(false))
+}
+
+// afterJournal reloads the sidebar after an undo or redo and selects path
+// if it still exists.
+func (m *Model) afterJournal(path string) {
+	m.index.Refresh(".", m.isArchiveDir)
+	m.updateNotes()
+	if m.cursor >= len(m.notes) {
+		m.cursor = len(m.notes) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if _, err := os.Lstat(path); err == nil {
+		m.revealNote(path)
+	}
+	m.updatePreview()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalUndoRedo(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"alpha.md": "# Alpha\n",
+		"inbox.md": "See [[alpha]]\n",
+	})
+	cfg := DefaultConfig()
+	cfg.NotesDir = root
+	cfg.ArchiveDir = filepath.Join(root, "archive")
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	file := filepath.Join(t.TempDir(), "journal.yaml")
+	j := OpenJournal(file, root)
+
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	// Make every kind of operation and record it as the TUI does
+	note, _ := createNote(".", "", "")
+	j.Record(Operation{Kind: opCreateNote, Path: note, Content: read(note)})
+	folder, _ := createFolder(".")
+	j.Record(Operation{Kind: opCreateFolder, Path: folder})
+	idx.Refresh(".", cfg.isArchiveDir)
+	plan := idx.planRename("alpha.md", "beta.md")
+	if err := plan.Apply(true); err != nil {
+		t.Fatal(err)
+	}
+	j.Record(Operation{Kind: opRename, Path: "alpha.md", To: "beta.md", Links: true})
+	archived, _ := archiveNote(cfg, "inbox.md")
+	j.Record(Operation{Kind: opArchive, Path: "inbox.md", Archive: filepath.Base(archived)})
+
+	// The journal survives a restart
+	j = OpenJournal(file, root)
+	if len(j.Done) != 4 {
+		t.Fatalf("reopened journal has %d operations, want 4", len(j.Done))
+	}
+
+	undo := func() {
+		t.Helper()
+		idx.Refresh(".", cfg.isArchiveDir)
+		if _, err := j.Undo(cfg, idx); err != nil {
+			t.Fatalf("Undo() error = %v", err)
+		}
+	}
+	redo := func() {
+		t.Helper()
+		idx.Refresh(".", cfg.isArchiveDir)
+		if _, err := j.Redo(cfg, idx); err != nil {
+			t.Fatalf("Redo() error = %v", err)
+		}
+	}
+
+	undo()
+	if read("inbox.md") != "See [[beta]]\n" {
+		t.Errorf("undoing the archive should restore inbox.md, got %q", read("inbox.md"))
+	}
+	undo()
+	if !exists("alpha.md") || exists("beta.md") || read("inbox.md") != "See [[alpha]]\n" {
+		t.Errorf("undoing the rename should move the note and its links back, inbox.md = %q", read("inbox.md"))
+	}
+	undo()
+	undo()
+	if exists(folder) || exists(note) {
+		t.Errorf("undoing the creations should remove %s and %s", folder, note)
+	}
+	if _, err := j.Undo(cfg, idx); err == nil {
+		t.Errorf("Undo() with an empty journal should fail")
+	}
+
+	redo()
+	redo()
+	redo()
+	redo()
+	if !exists(note) || !exists(folder) || !exists("beta.md") || exists("inbox.md") {
+		t.Errorf("redo did not repeat every operation")
+	}
+	undo()
+	if !exists("inbox.md") {
+		t.Errorf("an archive that was redone should be undoable again")
+	}
+
+	j.Record(Operation{Kind: opCreateFolder, Path: "other"})
+	if len(j.Undone) != 0 {
+		t.Errorf("a new operation should drop the redo history")
+	}
+}
+
+func TestJournalEditedNote(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	j := OpenJournal(filepath.Join(t.TempDir(), "journal.yaml"), root)
+
+	note, _ := createNote(".", "", "")
+	content, _ := os.ReadFile(note)
+	j.Record(Operation{Kind: opCreateNote, Path: note, Content: string(content)})
+	os.WriteFile(note, []byte("# Edited\n"), 0644)
+
+	if _, err := j.Undo(DefaultConfig(), newIndex("", root)); err == nil {
+		t.Errorf("undoing the creation of an edited note should fail")
+	}
+	if _, err := os.Stat(note); err != nil {
+		t.Errorf("edited note was removed: %v", err)
+	}
+}
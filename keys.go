@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions that can be bound to keys in the "keys" section of the config.
+const (
+	actionUp            = "up"
+	actionDown          = "down"
+	actionExpand        = "expand"
+	actionCollapse      = "collapse"
+	actionOpen          = "open"
+	actionEditInline    = "edit_inline"
+	actionRename        = "rename"
+	actionNewNote       = "new_note"
+	actionNewFolder     = "new_folder"
+	actionArchive       = "archive"
+	actionArchiveView   = "archive_view"
+	actionUndo          = "undo"
+	actionRedo          = "redo"
+	actionMessages      = "messages"
+	actionSearch        = "search"
+	actionQuickOpen     = "quick_open"
+	actionDaily         = "daily"
+	actionPrevDay       = "prev_day"
+	actionNextDay       = "next_day"
+	actionNextLink      = "next_link"
+	actionPrevLink      = "prev_link"
+	actionFollowLink    = "follow_link"
+	actionBack          = "back"
+	actionForward       = "forward"
+	actionBacklinks     = "backlinks"
+	actionTags          = "tags"
+	actionSort          = "sort"
+	actionReverseSort   = "reverse_sort"
+	actionMoveUp        = "move_up"
+	actionMoveDown      = "move_down"
+	actionTheme         = "theme"
+	actionToggleSidebar = "toggle_sidebar"
+	actionClear         = "clear"
+	actionQuit          = "quit"
+
+	// Only in the archive panel
+	actionRestore     = "restore"
+	actionRestoreHere = "restore_here"
+	actionPurge       = "purge"
+
+	// Only in the rename preview
+	actionUpdateLinks = "update_links"
+	actionSkipLinks   = "skip_links"
+	actionCancel      = "cancel"
+
+	// Only in the inline editor
+	actionSave           = "save"
+	actionToggleCheckbox = "toggle_checkbox"
+	actionLivePreview    = "live_preview"
+
+	// Only in the search and quick open lists
+	actionPrevResult = "prev_result"
+	actionNextResult = "next_result"
+)
+
+// Panels with actions of their own, whose keys may also be used in the tree.
+const (
+	panelArchive = "archive"
+	panelRename  = "rename"
+	panelEditor  = "editor"
+	panelPicker  = "picker" // search and quick open
+)
+
+// keyActions lists every action in help order with its default keys. Actions
+// without help text are left out of the footer, as are panel actions, which
+// are only shown in their panel's footer.
+var keyActions = []struct {
+	name, help string
+	keys       []string
+}{
+	{actionUp, "up", []string{"up", "k"}},
+	{actionDown, "down", []string{"down", "j"}},
+	{actionExpand, "expand", []string{"right", "l"}},
+	{actionCollapse, "collapse", []string{"left", "h"}},
+	{actionOpen, "edit", []string{"enter"}},
+	{actionEditInline, "edit inline", []string{"e"}},
+	{actionRename, "rename", []string{"r"}},
+	{actionNewNote, "new note", []string{"n"}},
+	{actionNewFolder, "new folder", []string{"N"}},
+	{actionArchive, "archive", []string{"backspace"}},
+	{actionArchiveView, "archived", []string{"A"}},
+	{actionUndo, "undo", []string{"u"}},
+	{actionRedo, "redo", []string{"ctrl+r"}},
+	{actionMessages, "messages", []string{"M"}},
+	{actionSearch, "search", []string{"/"}},
+	{actionQuickOpen, "open", []string{"ctrl+p"}},
+	{actionDaily, "today", []string{"d"}},
+	{actionPrevDay, "previous day", []string{"<"}},
+	{actionNextDay, "next day", []string{">"}},
+	{actionNextLink, "next link", []string{"g"}},
+	{actionPrevLink, "", []string{"G"}},
+	{actionFollowLink, "follow link", []string{"f"}},
+	{actionBack, "back", []string{"["}},
+	{actionForward, "forward", []string{"]"}},
+	{actionBacklinks, "backlinks", []string{"b"}},
+	{actionTags, "tags", []string{"t"}},
+	{actionSort, "sort", []string{"s"}},
+	{actionReverseSort, "", []string{"S"}},
+	{actionMoveUp, "", []string{"K"}},
+	{actionMoveDown, "", []string{"J"}},
+	{actionTheme, "theme", []string{"T"}},
+	{actionToggleSidebar, "sidebar", []string{"tab"}},
+	{actionClear, "", []string{"esc"}},
+	{actionQuit, "quit", []string{"q", "ctrl+c"}},
+
+	{actionRestore, "restore to original folder", []string{"r"}},
+	{actionRestoreHere, "restore to current folder", []string{"c"}},
+	{actionPurge, "delete", []string{"D"}},
+
+	{actionUpdateLinks, "rename and update links", []string{"y"}},
+	{actionSkipLinks, "rename only", []string{"s"}},
+	{actionCancel, "cancel", []string{"n"}},
+
+	{actionSave, "save", []string{"ctrl+s"}},
+	{actionToggleCheckbox, "toggle checkbox", []string{"ctrl+t"}},
+	{actionLivePreview, "live preview", []string{"ctrl+o"}},
+
+	{actionPrevResult, "", []string{"ctrl+p"}},
+	{actionNextResult, "", []string{"ctrl+n"}},
+}
+
+// actionPanels maps panel actions to the panel they apply in.
+var actionPanels = map[string]string{
+	actionRestore:     panelArchive,
+	actionRestoreHere: panelArchive,
+	actionPurge:       panelArchive,
+	actionUpdateLinks: panelRename,
+	actionSkipLinks:   panelRename,
+	actionCancel:      panelRename,
+
+	actionSave:           panelEditor,
+	actionToggleCheckbox: panelEditor,
+	actionLivePreview:    panelEditor,
+
+	actionPrevResult: panelPicker,
+	actionNextResult: panelPicker,
+}
+
+// KeyMap maps a key, as reported by tea.KeyMsg.String, to its action. Keys of
+// panel actions are stored as "panel:key", since they only apply there.
+type KeyMap map[string]string
+
+// in returns the action of key in panel: a panel action if the key has one
+// there, otherwise its action in the tree.
+func (k KeyMap) in(panel, key string) string {
+	if action, ok := k[panel+":"+key]; ok {
+		return action
+	}
+	return k[key]
+}
+
+// inInput is the action of msg in a panel that takes text, where keys that
+// type a character are left to the input.
+func (k KeyMap) inInput(panel string, msg tea.KeyMsg) string {
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		return ""
+	}
+	return k.in(panel, msg.String())
+}
+
+// inputBindings leaves out of bindings the keys that type a character, which
+// do not work in panels that take text.
+func inputBindings(bindings map[string][]string) map[string][]string {
+	filtered := make(map[string][]string)
+	for action, keys := range bindings {
+		for _, key := range keys {
+			if utf8.RuneCountInString(key) > 1 {
+				filtered[action] = append(filtered[action], key)
+			}
+		}
+	}
+	return filtered
+}
+
+func DefaultKeys() map[string][]string {
+	keys := make(map[string][]string)
+	for _, action := range keyActions {
+		keys[action.name] = append([]string(nil), action.keys...)
+	}
+	return keys
+}
+
+// mergeKeys lays the bindings set in the config file over the defaults. A
+// default key that the user bound to another action is taken from the
+// default action, so that defaults added in new versions never clash with an
+// existing config; the returned warnings say which keys gave way.
+func mergeKeys(user map[string][]string) (map[string][]string, []string) {
+	bound := make(map[string]string)
+	for action, keys := range user {
+		for _, key := range keys {
+			bound[actionPanels[action]+":"+key] = action
+		}
+	}
+
+	merged := DefaultKeys()
+	var warnings []string
+	for _, action := range keyActions {
+		if keys, ok := user[action.name]; ok {
+			merged[action.name] = keys
+			continue
+		}
+		var kept []string
+		for _, key := range merged[action.name] {
+			if other, ok := bound[actionPanels[action.name]+":"+key]; ok {
+				warnings = append(warnings, fmt.Sprintf("%q is bound to %s in the config, so %s no longer uses it", key, other, action.name))
+				continue
+			}
+			kept = append(kept, key)
+		}
+		merged[action.name] = kept
+	}
+	// Unknown actions are kept for NewKeyMap to report
+	for action, keys := range user {
+		if _, ok := merged[action]; !ok {
+			merged[action] = keys
+		}
+	}
+	return merged, warnings
+}
+
+// NewKeyMap builds the key lookup for bindings, rejecting unknown actions and
+// keys bound to more than one action.
+func NewKeyMap(bindings map[string][]string) (KeyMap, error) {
+	known := make(map[string]bool)
+	for _, action := range keyActions {
+		known[action.name] = true
+	}
+
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	keyMap := make(KeyMap)
+	for _, action := range actions {
+		if !known[action] {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		for _, key := range bindings[action] {
+			if key == "" {
+				return nil, fmt.Errorf("empty key bound to %s", action)
+			}
+			bound := key
+			if panel := actionPanels[action]; panel != "" {
+				bound = panel + ":" + key
+			}
+			if other, ok := keyMap[bound]; ok && other != action {
+				return nil, fmt.Errorf("key %q is bound to both %s and %s", key, other, action)
+			}
+			keyMap[bound] = action
+		}
+	}
+	return keyMap, nil
+}
+
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// keyLabel shows keys the way the footer does, as in "↑/k".
+func keyLabel(keys []string) string {
+	var labels []string
+	for _, key := range keys {
+		if symbol, ok := keySymbols[key]; ok {
+			key = symbol
+		}
+		labels = append(labels, key)
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyHelp renders the footer help line from the live bindings.
+func keyHelp(bindings map[string][]string) string {
+	var parts []string
+	for _, action := range keyActions {
+		keys := bindings[action.name]
+		if action.help == "" || actionPanels[action.name] != "" || len(keys) == 0 {
+			continue
+		}
+		parts = append(parts, keyLabel(keys)+": "+action.help)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// panelHelp renders a panel's footer from the live bindings. Each entry
+// lists the actions that do one thing in the panel followed by what they do,
+// as in "up down: select". Without a description, the help of the last
+// action is used. Unbound actions are left out.
+func panelHelp(bindings map[string][]string, entries ...string) string {
+	var parts []string
+	for _, entry := range entries {
+		names, help, _ := strings.Cut(entry, ":")
+		var labels []string
+		for _, name := range strings.Fields(names) {
+			if keys := bindings[name]; len(keys) > 0 {
+				labels = append(labels, keyLabel(keys))
+			}
+		}
+		if help == "" {
+			fields := strings.Fields(names)
+			for _, action := range keyActions {
+				if action.name == fields[len(fields)-1] {
+					help = action.help
+				}
+			}
+		}
+		if len(labels) > 0 {
+			parts = append(parts, strings.Join(labels, ",")+": "+strings.TrimSpace(help))
+		}
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	keys, err := NewKeyMap(DefaultKeys())
+	if err != nil {
+		t.Fatalf("default keys: %v", err)
+	}
+	if keys["k"] != actionUp || keys["ctrl+c"] != actionQuit || keys["backspace"] != actionArchive {
+		t.Errorf("default key map = %v", keys)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		key     string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "override keeps other defaults",
+			config: "keys:\n  search: [\"ctrl+f\"]\n",
+			key:    "ctrl+f",
+			want:   actionSearch,
+		},
+		{
+			name:   "replaced key is unbound",
+			config: "keys:\n  search: [\"ctrl+f\"]\n",
+			key:    "/",
+			want:   "",
+		},
+		{
+			name:    "conflict",
+			config:  "keys:\n  tags: [\"q\"]\n",
+			wantErr: `key "q" is bound to both quit and tags`,
+		},
+		{
+			name:    "unknown action",
+			config:  "keys:\n  explode: [\"x\"]\n",
+			wantErr: `unknown action "explode"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			if err := yaml.Unmarshal([]byte(tt.config), cfg); err != nil {
+				t.Fatal(err)
+			}
+			keys, err := NewKeyMap(cfg.Keys)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("NewKeyMap() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeyMap() error = %v", err)
+			}
+			if keys[tt.key] != tt.want {
+				t.Errorf("keys[%q] = %q, want %q", tt.key, keys[tt.key], tt.want)
+			}
+			if keys["k"] != actionUp {
+				t.Errorf("default binding for k lost: %q", keys["k"])
+			}
+		})
+	}
+}
+
+func TestKeyHelp(t *testing.T) {
+	keys := DefaultKeys()
+	keys[actionSearch] = []string{"ctrl+f"}
+	help := keyHelp(keys)
+	if !strings.HasPrefix(help, "↑/k: up • ↓/j: down") {
+		t.Errorf("keyHelp() = %q", help)
+	}
+	if !strings.Contains(help, "ctrl+f: search") || strings.Contains(help, "/: search") {
+		t.Errorf("keyHelp() should show live bindings, got %q", help)
+	}
+	if !strings.Contains(help, "<: previous day • >: next day") {
+		t.Errorf("keyHelp() should list daily note navigation, got %q", help)
+	}
+}
+
+func TestPanelKeys(t *testing.T) {
+	bindings := DefaultKeys()
+	bindings[actionPurge] = []string{"x"}
+	bindings[actionDown] = []string{"down", "ctrl+n"}
+	bindings[actionSkipLinks] = nil
+	keys, err := NewKeyMap(bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		panel, key, want string
+	}{
+		{panelArchive, "r", actionRestore},
+		{panelArchive, "x", actionPurge},
+		{panelArchive, "D", ""},
+		{panelArchive, "ctrl+n", actionDown},
+		{panelRename, "n", actionCancel},
+		{panelRename, "r", actionRename},
+	}
+	for _, tt := range tests {
+		if got := keys.in(tt.panel, tt.key); got != tt.want {
+			t.Errorf("keys.in(%q, %q) = %q, want %q", tt.panel, tt.key, got, tt.want)
+		}
+	}
+	if keys["r"] != actionRename || keys["n"] != actionNewNote {
+		t.Errorf("panel actions should not take over the tree's keys")
+	}
+
+	help := panelHelp(bindings, "up down: select", "open restore", "purge", "skip_links")
+	if help != "↑/k,↓/ctrl+n: select • enter,r: restore to original folder • x: delete" {
+		t.Errorf("panelHelp() = %q", help)
+	}
+	input := inputBindings(bindings)
+	if help := panelHelp(input, "save", "clear: close", "quit"); help != "ctrl+s: save • esc: close • ctrl+c: quit" {
+		t.Errorf("panelHelp() for a text panel = %q, want keys that type leaving out", help)
+	}
+	if got := keys.inInput(panelEditor, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); got != "" {
+		t.Errorf("q in the editor = %q, want it typed", got)
+	}
+	if got := keys.inInput(panelPicker, tea.KeyMsg{Type: tea.KeyCtrlP}); got != actionPrevResult {
+		t.Errorf("ctrl+p in the switcher = %q, want %q", got, actionPrevResult)
+	}
+	if strings.Contains(keyHelp(bindings), "restore") {
+		t.Errorf("keyHelp() should leave out panel actions: %q", keyHelp(bindings))
+	}
+}
+
+func TestMergeKeys(t *testing.T) {
+	// A config written before "d" opened the daily note
+	keys, warnings := mergeKeys(map[string][]string{
+		actionTags:   {"d"},
+		actionSearch: {"/"},
+		actionCancel: {"r"},
+	})
+	keyMap, err := NewKeyMap(keys)
+	if err != nil {
+		t.Fatalf("colliding defaults should give way: %v", err)
+	}
+	if keyMap["d"] != actionTags || len(keys[actionDaily]) != 0 {
+		t.Errorf("d = %q, daily keys = %v", keyMap["d"], keys[actionDaily])
+	}
+	if keyMap["t"] != "" || keyMap["k"] != actionUp {
+		t.Errorf("replaced binding t = %q, default k = %q", keyMap["t"], keyMap["k"])
+	}
+	if keyMap["r"] != actionRename || keyMap.in(panelRename, "r") != actionCancel {
+		t.Errorf("a panel binding should not take keys from the tree")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"d" is bound to tags`) {
+		t.Errorf("warnings = %q", warnings)
+	}
+
+	// Keys the user bound twice are still an error
+	keys, _ = mergeKeys(map[string][]string{actionTags: {"q"}, actionQuit: {"q"}})
+	if _, err := NewKeyMap(keys); err == nil {
+		t.Errorf("conflicting user bindings should be rejected")
+	}
+}
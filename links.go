@@ -0,0 +1,243 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Private-use runes bracket the active link in the markdown handed to
+// glamour so it can be found and restyled in the rendered output.
+const (
+	linkMarkStart = "\uE000"
+	linkMarkEnd   = "\uE001"
+)
+
+var markedLinkRe = regexp.MustCompile(linkMarkStart + `(?s)(.*?)` + linkMarkEnd)
+
+type Link struct {
+	Start, End int    // byte positions of the [[...]] span in the note source
+	Target     string // the linked note path or title
+}
+
+// Minimal extractor for [[wikilink]] style. An alias after "|" is dropped
+// from the target.
+func extractLinks(s string) []Link {
+	var links []Link
+	offset := 0
+	for {
+		start := strings.Index(s[offset:], "[[")
+		if start == -1 {
+			break
+		}
+		start += offset
+		end := strings.Index(s[start:], "]]")
+		if end == -1 {
+			break
+		}
+		end += start + 2
+		target := s[start+2 : end-2]
+		if i := strings.Index(target, "|"); i >= 0 {
+			target = target[:i]
+		}
+		links = append(links, Link{Start: start, End: end, Target: strings.TrimSpace(target)})
+		offset = end
+	}
+	return links
+}
+
+// linkKind records how a wikilink target matched a note.
+type linkKind int
+
+const (
+	linkUnresolved linkKind = iota
+	linkRelative
+	linkRoot
+	linkName
+	linkTitle
+	linkAlias
+)
+
+// ResolveLink finds the note a wikilink target refers to. It tries, in order,
+// a path relative to the linking note, a path from the vault root, a file
+// name, a note title and finally a frontmatter alias. Headings after "#" are
+// ignored.
+func (idx *Index) ResolveLink(target, from string) (string, bool) {
+	path, kind := idx.resolveLink(target, from)
+	return path, kind != linkUnresolved
+}
+
+func (idx *Index) resolveLink(target, from string) (string, linkKind) {
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", linkUnresolved
+	}
+
+	candidate := filepath.Clean(filepath.FromSlash(target))
+	if !strings.HasSuffix(candidate, ".md") {
+		candidate += ".md"
+	}
+	if path := filepath.Join(filepath.Dir(from), candidate); idx.Entries[path] != nil {
+		return path, linkRelative
+	}
+	if idx.Entries[candidate] != nil {
+		return candidate, linkRoot
+	}
+
+	if idx.names == nil {
+		idx.buildLookups()
+	}
+	key := strings.ToLower(target)
+	if paths := idx.names[key]; len(paths) > 0 {
+		return paths[0], linkName
+	}
+	if paths := idx.titles[key]; len(paths) > 0 {
+		return paths[0], linkTitle
+	}
+	if paths := idx.aliases[key]; len(paths) > 0 {
+		return paths[0], linkAlias
+	}
+	return "", linkUnresolved
+}
+
+// buildLookups indexes note paths by lowercased file name, title and alias.
+// Each list is sorted so ambiguous targets resolve to the same note every time.
+func (idx *Index) buildLookups() {
+	idx.names = make(map[string][]string)
+	idx.titles = make(map[string][]string)
+	idx.aliases = make(map[string][]string)
+	for path, entry := range idx.Entries {
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".md"))
+		idx.names[name] = append(idx.names[name], path)
+		title := strings.ToLower(entry.Title)
+		idx.titles[title] = append(idx.titles[title], path)
+		for _, alias := range entry.Aliases {
+			alias = strings.ToLower(alias)
+			idx.aliases[alias] = append(idx.aliases[alias], path)
+		}
+	}
+	for _, paths := range idx.names {
+		sort.Strings(paths)
+	}
+	for _, paths := range idx.titles {
+		sort.Strings(paths)
+	}
+	for _, paths := range idx.aliases {
+		sort.Strings(paths)
+	}
+}
+
+// markSpan wraps content[start:end] with marker runes.
+func markSpan(content string, start, end int) string {
+	if start < 0 || end > len(content) || start >= end {
+		return content
+	}
+	return content[:start] + linkMarkStart + content[start:end] + linkMarkEnd + content[end:]
+}
+
+// highlightMarked restyles the marked span in rendered output and returns
+// the line it starts on, or -1 if nothing is marked.
+func highlightMarked(rendered string, style lipgloss.Style) (string, int) {
+	line := -1
+	if i := strings.Index(rendered, linkMarkStart); i >= 0 {
+		line = strings.Count(rendered[:i], "\n")
+	}
+	rendered = markedLinkRe.ReplaceAllStringFunc(rendered, func(s string) string {
+		inner := strings.TrimSuffix(strings.TrimPrefix(s, linkMarkStart), linkMarkEnd)
+		var parts []string
+		for _, part := range strings.Split(ansi.Strip(inner), "\n") {
+			parts = append(parts, style.Render(part))
+		}
+		return strings.Join(parts, "\n")
+	})
+	rendered = strings.NewReplacer(linkMarkStart, "", linkMarkEnd, "").Replace(rendered)
+	return rendered, line
+}
+
+func (m Model) currentNotePath() string {
+	if len(m.notes) == 0 || m.cursor >= len(m.notes) || m.notes[m.cursor].isDir {
+		return ""
+	}
+	return m.notes[m.cursor].path
+}
+
+// cycleLink moves the link highlight by delta and scrolls it into view.
+func (m *Model) cycleLink(delta int) {
+	if len(m.links) == 0 {
+		return
+	}
+	if m.activeLink < 0 {
+		if delta > 0 {
+			m.activeLink = 0
+		} else {
+			m.activeLink = len(m.links) - 1
+		}
+	} else {
+		m.activeLink = (m.activeLink + delta + len(m.links)) % len(m.links)
+	}
+
+	line := m.renderPreview()
+	if line >= 0 && (line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height) {
+		m.viewport.SetYOffset(line)
+	}
+}
+
+func (m *Model) followLink() {
+	if m.activeLink < 0 || m.activeLink >= len(m.links) {
+		return
+	}
+	from := m.currentNotePath()
+	target := m.links[m.activeLink].Target
+	path, ok := m.index.ResolveLink(target, from)
+	if !ok {
+		m.notifyError("No note matches [[%s]]", target)
+		return
+	}
+	if path == from {
+		return
+	}
+	if m.openNote(path) {
+		m.history = append(m.history, from)
+		m.forward = nil
+	}
+}
+
+func (m *Model) historyBack() {
+	if len(m.history) == 0 {
+		return
+	}
+	from := m.currentNotePath()
+	path := m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	if m.openNote(path) && from != "" {
+		m.forward = append(m.forward, from)
+	}
+}
+
+func (m *Model) historyForward() {
+	if len(m.forward) == 0 {
+		return
+	}
+	from := m.currentNotePath()
+	path := m.forward[len(m.forward)-1]
+	m.forward = m.forward[:len(m.forward)-1]
+	if m.openNote(path) && from != "" {
+		m.history = append(m.history, from)
+	}
+}
+
+// openNote reveals path in the sidebar and shows it in the preview.
+func (m *Model) openNote(path string) bool {
+	if !m.revealNote(path) {
+		return false
+	}
+	m.updatePreview()
+	return true
+}
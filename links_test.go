@@ -0,0 +1,95 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestExtractLinks(t *testing.T) {
+	content := "See [[Other note]] and [[folder/deep|alias]] but not [[unclosed"
+	links := extractLinks(content)
+	want := []string{"Other note", "folder/deep"}
+	if len(links) != len(want) {
+		t.Fatalf("extractLinks() returned %d links, want %d", len(links), len(want))
+	}
+	for i, link := range links {
+		if link.Target != want[i] {
+			t.Errorf("link %d target = %v, want %v", i, link.Target, want[i])
+		}
+		if !strings.HasPrefix(content[link.Start:link.End], "[[") || !strings.HasSuffix(content[link.Start:link.End], "]]") {
+			t.Errorf("link %d span = %q, want the [[...]] text", i, content[link.Start:link.End])
+		}
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	idx := newIndex("", ".")
+	idx.Entries = map[string]*IndexEntry{
+		"inbox.md":               {Title: "Inbox"},
+		"projects/alpha.md":      {Title: "Project Alpha"},
+		"projects/notes/todo.md": {Title: "Todo"},
+		"archive-notes/todo.md":  {Title: "Old todo"},
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		from   string
+		want   string
+		found  bool
+	}{
+		{name: "relative to linking note", target: "notes/todo", from: "projects/alpha.md", want: "projects/notes/todo.md", found: true},
+		{name: "from vault root", target: "projects/alpha.md", from: "inbox.md", want: "projects/alpha.md", found: true},
+		{name: "by file name", target: "alpha", from: "inbox.md", want: "projects/alpha.md", found: true},
+		{name: "by title", target: "project alpha", from: "inbox.md", want: "projects/alpha.md", found: true},
+		{name: "heading is ignored", target: "Inbox#Today", from: "projects/alpha.md", want: "inbox.md", found: true},
+		{name: "ambiguous name picks first path", target: "todo", from: "inbox.md", want: filepath.Join("archive-notes", "todo.md"), found: true},
+		{name: "unknown target", target: "missing", from: "inbox.md", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idx.ResolveLink(tt.target, tt.from)
+			if ok != tt.found || got != tt.want {
+				t.Errorf("ResolveLink(%q) = %v, %v, want %v, %v", tt.target, got, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestFollowUnresolvedLink(t *testing.T) {
+	m := Model{
+		index:      newIndex("", "."),
+		notes:      []Note{{path: "inbox.md", title: "Inbox"}},
+		links:      []Link{{Target: "Missing"}},
+		activeLink: 0,
+	}
+	m.followLink()
+	if len(m.messages) != 1 || !m.messages[0].isErr || m.messages[0].text != "No note matches [[Missing]]" {
+		t.Errorf("messages = %+v", m.messages)
+	}
+}
+
+func TestHighlightMarked(t *testing.T) {
+	content := "line one\nsee [[a]] and [[b]]"
+	link := extractLinks(content)[1]
+	marked := markSpan(content, link.Start, link.End)
+	if !strings.Contains(marked, linkMarkStart+"[[b]]"+linkMarkEnd) {
+		t.Fatalf("markSpan() = %q, want [[b]] marked", marked)
+	}
+
+	rendered, line := highlightMarked(marked, lipgloss.NewStyle())
+	if line != 1 {
+		t.Errorf("highlightMarked() line = %d, want 1", line)
+	}
+	if strings.ContainsAny(rendered, linkMarkStart+linkMarkEnd) {
+		t.Errorf("highlightMarked() left markers in %q", rendered)
+	}
+
+	if got := markSpan(content, -1, -1); got != content {
+		t.Errorf("markSpan() with an empty span changed content")
+	}
+}
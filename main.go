@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,30 +23,74 @@ type Note struct {
 	isDir                bool
 	depth                int
 	expanded             bool // Track if folder is expanded
-}
-
-type Link struct {
-    Start, End int    // byte positions in the rendered string
-    Target     string // the linked note path or title
+	tags                 []string
+	meta                 Frontmatter
 }
 
 type Model struct {
-	config        *Config
-	notes         []Note
-	cursor        int
-	viewport      viewport.Model
-	showSidebar   bool
-	width, height int
-	styles        Styles
-	textInput     textinput.Model
-	renaming      bool
-	mdRenderer    *glamour.TermRenderer
-	links       []Link
-    activeLink  int // index of the currently highlighted link
+	config          *Config
+	notes           []Note
+	cursor          int
+	viewport        viewport.Model
+	showSidebar     bool
+	width, height   int
+	styles          Styles
+	textInput       textinput.Model
+	renaming        bool
+	mdRenderer      *glamour.TermRenderer
+	links           []Link
+	activeLink      int      // index of the currently highlighted link, -1 for none
+	history         []string // notes visited before following a link
+	forward         []string // notes left by going back
+	preview         string   // rendered markdown of the current note
+	searching       bool
+	searchInput     textinput.Model
+	searchResults   []SearchResult
+	searchCursor    int
+	index           *Index
+	switching       bool
+	switcherInput   textinput.Model
+	switcherResults []SwitchResult
+	switcherCursor  int
+	paragraph       *span // highlighted paragraph after opening a backlink
+	backlinks       []Backlink
+	backlinkCursor  int
+	showBacklinks   bool
+	pendingRename   *RenamePlan // rename waiting for link update confirmation
+	renameScroll    int
+	tagView         bool
+	tagRows         []tagRow
+	tagCursor       int
+	tagExpanded     map[string]bool
+	theme           Theme
+	keys            KeyMap
+	journal         *Journal
+	archiveView     bool
+	archiveEntries  []ArchiveEntry
+	archiveCursor   int
+	confirmPurge    bool
+	messages        []message
+	toast           *message // notification shown in the status bar
+	toastID         int
+	showMessages    bool
+	messageScroll   int
+	debugLog        *log.Logger
+	watcher         *noteWatcher
+	previews        *previewCache
+	pendingRender   *renderJob     // render for the Update wrapper to start
+	previewPending  bool           // the preview shows the source until its render is done
+	previewTarget   *previewTarget // where to scroll the preview once it is rendered
+	sidebarOffset   int            // first tree row shown in the sidebar
+	editing         bool           // inline editor open in the content pane
+	editor          textarea.Model
+	editPath        string
+	editSaved       string // contents as last read or saved
+	editRendered    string // live preview of the editor contents
+	editPreview     bool
+	confirmDiscard  bool
+	confirmQuit     bool // quitting from the editor with unsaved changes
 }
 
-
-
 var version = "dev"
 
 func printVersion() {
@@ -54,31 +98,68 @@ func printVersion() {
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.toast != nil {
+		return tea.Batch(m.clearToastAfter(), m.watcher.wait())
+	}
+	return m.watcher.wait()
 }
 
+// Update handles msg, schedules hiding any notification it raised, starts
+// any preview render it queued and scrolls the sidebar to the cursor.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(clearToastMsg); ok {
+		if msg.id == m.toastID {
+			m.toast = nil
+		}
+		return m, nil
+	}
+
+	toastID := m.toastID
+	model, cmd := m.update(msg)
+	next, ok := model.(Model)
+	if !ok {
+		return model, cmd
+	}
+	if next.toastID != toastID {
+		cmd = tea.Batch(cmd, next.clearToastAfter())
+	}
+	if next.pendingRender != nil {
+		cmd = tea.Batch(cmd, next.renderAsync(*next.pendingRender))
+		next.pendingRender = nil
+	}
+	next.scrollSidebar()
+	return next, cmd
+}
+
+// quit saves the session and stops watching before the program exits.
+func (m *Model) quit() tea.Cmd {
+	m.saveSession()
+	m.watcher.Close()
+	return tea.Quit
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	if m.editing {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			return m.updateInlineEditor(msg)
+		case tea.WindowSizeMsg, notesChangedMsg, previewRenderedMsg:
+			// Handled below as in normal mode
+		default:
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+		}
+	}
+
 	if m.renaming {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch msg.Type {
 			case tea.KeyEnter:
-				if m.textInput.Value() != "" {
-					currentPath := m.notes[m.cursor].path
-					newPath := filepath.Join(filepath.Dir(currentPath), m.textInput.Value())
-					if err := os.Rename(currentPath, newPath); err == nil {
-						m.updateNotes()
-						for i, note := range m.notes {
-							if note.path == newPath {
-								m.cursor = i
-								break
-							}
-						}
-					}
-				}
+				m.startRename()
 				m.renaming = false
 				m.textInput.Blur()
 				return m, nil
@@ -92,9 +173,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.pendingRename != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateRenamePreview(msg)
+		}
+	}
+
+	if m.showMessages {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateMessages(msg)
+		}
+	}
+
+	if m.searching {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSearch(msg)
+		}
+	}
+
+	if m.switching {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSwitcher(msg)
+		}
+	}
+
+	if m.showBacklinks {
+		if msg, ok := msg.(tea.KeyMsg); ok && m.keys[msg.String()] != actionQuit {
+			return m.updateBacklinks(msg)
+		}
+	}
+
+	if m.tagView {
+		if msg, ok := msg.(tea.KeyMsg); ok && m.keys[msg.String()] != actionQuit {
+			return m.updateTagView(msg)
+		}
+	}
+
+	if m.archiveView {
+		if msg, ok := msg.(tea.KeyMsg); ok && (m.confirmPurge || m.keys[msg.String()] != actionQuit) {
+			return m.updateArchiveView(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
+		offset := m.previewOffset()
 		heights := m.config.CalculateHeights(msg.Height)
 		paddingH := m.config.Layout.Padding.Horizontal
 
@@ -103,44 +227,79 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			viewportWidth := m.width - sidebarWidth - (paddingH * 4)
 			m.viewport = viewport.New(viewportWidth, heights.Content)
 			if m.mdRenderer != nil {
-				m.mdRenderer, _ = glamour.NewTermRenderer(
-					glamour.WithAutoStyle(),
-					glamour.WithWordWrap(viewportWidth-4),
-				)
+				m.mdRenderer, _ = newMarkdownRenderer(m.theme, viewportWidth-4)
 			}
 		} else {
 			viewportWidth := m.width - (paddingH * 2)
 			m.viewport = viewport.New(viewportWidth, heights.Content)
 			if m.mdRenderer != nil {
-				m.mdRenderer, _ = glamour.NewTermRenderer(
-					glamour.WithAutoStyle(),
-					glamour.WithWordWrap(viewportWidth-4),
-				)
+				m.mdRenderer, _ = newMarkdownRenderer(m.theme, viewportWidth-4)
 			}
 		}
 		m.viewport.YPosition = heights.Header
 		m.viewport.Style = m.styles.viewport
+		if m.editing {
+			m.resizeEditor()
+			m.refreshEditPreview()
+		}
 		m.updatePreview()
+		m.scrollPreview(previewTarget{offset: offset})
+
+	case editorFinishedMsg:
+		m.editorFinished(msg)
+
+	case notesChangedMsg:
+		m.notesChanged(msg)
+		return m, m.watcher.wait()
+
+	case previewRenderedMsg:
+		m.previewRendered(msg)
 
 	case tea.KeyMsg:
 		// Normal mode handling
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "tab":
+		switch m.keys[msg.String()] {
+		case actionQuit:
+			return m, m.quit()
+		case actionToggleSidebar:
 			m.showSidebar = !m.showSidebar
 			return m, nil
-		case "up", "k":
+		case actionSearch:
+			m.searching = true
+			m.index.Refresh(".", m.isArchiveDir)
+			m.saveIndex()
+			m.searchInput.SetValue("")
+			m.searchResults = nil
+			m.searchCursor = 0
+			m.searchInput.Focus()
+			return m, textinput.Blink
+		case actionQuickOpen:
+			m.switching = true
+			m.index.Refresh(".", m.isArchiveDir)
+			m.saveIndex()
+			m.switcherInput.SetValue("")
+			m.runSwitcher()
+			m.switcherInput.Focus()
+			return m, textinput.Blink
+		case actionDaily:
+			m.openDailyNote(today())
+			return m, nil
+		case actionPrevDay:
+			m.stepDaily(-1)
+			return m, nil
+		case actionNextDay:
+			m.stepDaily(1)
+			return m, nil
+		case actionUp:
 			if m.cursor > 0 {
 				m.cursor--
 				m.updatePreview()
 			}
-		case "down", "j":
+		case actionDown:
 			if m.cursor < len(m.notes)-1 {
 				m.cursor++
 				m.updatePreview()
 			}
-		case "enter":
+		case actionOpen:
 			if len(m.notes) > 0 {
 				current := m.notes[m.cursor]
 				if current.isDir {
@@ -149,43 +308,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.textInput.SetValue(current.title)
 					m.textInput.Focus()
 					return m, textinput.Blink
+				} else if m.config.InlineEditor {
+					return m, m.startInlineEdit()
 				} else {
 					// Only open editor for files
-					cmd := exec.Command(m.config.GetEditor(), current.path)
-					cmd.Stdin = os.Stdin
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-					tea.ExitAltScreen()
-					cmd.Run()
-					tea.EnterAltScreen()
-					m.updateNotes()
-					m.updatePreview()
-					return m, tea.ClearScreen
+					return m, m.openEditor()
 				}
 			}
-		case "N":
-			currentDir := m.getCurrentDirectory()
-			baseName := "New Folder"
-			newPath := filepath.Join(currentDir, baseName)
-
-			// Find unique name if folder exists
-			counter := 1
-			for {
-				if _, err := os.Stat(newPath); os.IsNotExist(err) {
-					break
-				}
-				newPath = filepath.Join(currentDir, fmt.Sprintf("%s %d", baseName, counter))
-				counter++
+		case actionEditInline:
+			if len(m.notes) > 0 && !m.notes[m.cursor].isDir {
+				return m, m.startInlineEdit()
 			}
-
-			if err := os.MkdirAll(newPath, 0755); err == nil {
-				m.updateNotes()
-				// Find and select the new folder
-				for i, note := range m.notes {
-					if note.path == newPath {
-						m.cursor = i
-						break
-					}
+		case actionNewFolder:
+			newPath, err := createFolder(m.getCurrentDirectory())
+			if err != nil {
+				m.notifyError("Could not create folder: %v", err)
+				return m, nil
+			}
+			m.record(Operation{Kind: opCreateFolder, Path: newPath})
+			m.updateNotes()
+			// Find and select the new folder
+			for i, note := range m.notes {
+				if note.path == newPath {
+					m.cursor = i
+					break
 				}
 			}
 
@@ -193,16 +339,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textInput.SetValue(filepath.Base(newPath))
 			m.textInput.Focus()
 			return m, textinput.Blink
-		case "n":
-			currentDir := m.getCurrentDirectory()
-
-			timestamp := time.Now().Format("2006-01-02-150405")
-			filename := filepath.Join(currentDir, fmt.Sprintf("note-%s.md", timestamp))
-
-			content := fmt.Sprintf("# New Note\n\nCreated: %s\n",
-				time.Now().Format("2006-01-02 15:04:05"))
-
-			if err := os.WriteFile(filename, []byte(content), 0644); err == nil {
+		case actionNewNote:
+			filename, err := createNote(m.getCurrentDirectory(), "", "")
+			if err != nil {
+				m.notifyError("Could not create note: %v", err)
+			} else {
+				content, _ := os.ReadFile(filename)
+				m.record(Operation{Kind: opCreateNote, Path: filename, Content: string(content)})
+				m.notify("Created %s", filename)
 				m.updateNotes()
 				// Find and select the new note
 				for i, note := range m.notes {
@@ -213,12 +357,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-		case "right", "l":
+		case actionExpand:
 			if len(m.notes) > 0 && m.notes[m.cursor].isDir {
 				m.notes[m.cursor].expanded = true
 				m.updateNotes()
 			}
-		case "left", "h":
+		case actionCollapse:
 			if len(m.notes) > 0 {
 				if m.notes[m.cursor].isDir {
 					m.notes[m.cursor].expanded = false
@@ -235,49 +379,96 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-		case "backspace":
+		case actionArchive:
 			if !m.renaming && len(m.notes) > 0 {
-				note := m.notes[m.cursor]
-
-				// Create archive directory if it doesn't exist
-				if err := os.MkdirAll(m.config.ArchiveDir, 0755); err == nil {
-					// Generate unique name to avoid conflicts
-					baseName := filepath.Base(note.path)
-					timestamp := time.Now().Format("2006-01-02-150405")
-					archiveName := fmt.Sprintf("%s-%s", timestamp, baseName)
-					archivePath := filepath.Join(m.config.ArchiveDir, archiveName)
-
-					// Move the file/folder to archive
-					if err := os.Rename(note.path, archivePath); err == nil {
-						// Update cursor position
-						if m.cursor > 0 {
-							m.cursor--
-						}
-						m.updateNotes()
-						m.updatePreview()
-					}
+				path := m.notes[m.cursor].path
+				archivePath, err := archiveNote(m.config, path)
+				if archivePath == "" {
+					m.notifyError("Could not archive %s: %v", path, err)
+					return m, nil
+				}
+				m.record(Operation{Kind: opArchive, Path: path, Archive: filepath.Base(archivePath)})
+				if err != nil {
+					m.notifyError("Archived %s but could not record where it came from: %v", path, err)
+				} else {
+					m.notify("Archived %s • u to undo", path)
 				}
+				// Update cursor position
+				if m.cursor > 0 {
+					m.cursor--
+				}
+				m.updateNotes()
+				m.updatePreview()
 			}
 			return m, nil
-		
-		case "g":
-		    if len(m.links) > 0 {
-		        m.activeLink = (m.activeLink + 1) % len(m.links)
-		        // scroll viewport to link position
-		        m.viewport.ScrollTo(m.links[m.activeLink].Start)
-		    }
-		case "enter":
-		    if len(m.links) > 0 && m.activeLink < len(m.links) {
-		        target := m.links[m.activeLink].Target
-		        // Find the note with that title and open it
-		        for i, note := range m.notes {
-		            if note.title == target {
-		                m.cursor = i
-		                m.updatePreview()
-		                break
-		            }
-		        }
-		    }
+
+		case actionMessages:
+			m.showMessages = true
+			m.messageScroll = 0
+			return m, nil
+		case actionUndo:
+			m.undo()
+			return m, nil
+		case actionRedo:
+			m.redo()
+			return m, nil
+		case actionNextLink:
+			m.cycleLink(1)
+		case actionPrevLink:
+			m.cycleLink(-1)
+		case actionFollowLink:
+			m.followLink()
+			return m, nil
+		case actionRename:
+			if len(m.notes) > 0 {
+				m.renaming = true
+				m.textInput.SetValue(filepath.Base(m.notes[m.cursor].path))
+				m.textInput.Focus()
+				return m, textinput.Blink
+			}
+		case actionArchiveView:
+			m.archiveView = true
+			m.archiveCursor = 0
+			m.loadArchive()
+			return m, nil
+		case actionTags:
+			m.tagView = true
+			m.index.Refresh(".", m.isArchiveDir)
+			m.saveIndex()
+			m.loadTagRows()
+			return m, nil
+		case actionSort:
+			m.cycleSortMode()
+			return m, nil
+		case actionReverseSort:
+			m.toggleSortDirection()
+			return m, nil
+		case actionMoveUp:
+			m.moveManual(-1)
+			return m, nil
+		case actionMoveDown:
+			m.moveManual(1)
+			return m, nil
+		case actionTheme:
+			m.cycleTheme()
+			return m, nil
+		case actionBacklinks:
+			if m.currentNotePath() != "" {
+				m.showBacklinks = true
+				m.loadBacklinks()
+			}
+			return m, nil
+		case actionBack:
+			m.historyBack()
+			return m, nil
+		case actionForward:
+			m.historyForward()
+			return m, nil
+		case actionClear:
+			if m.activeLink >= 0 {
+				m.activeLink = -1
+				m.renderPreview()
+			}
 		}
 
 		// Add viewport key handling
@@ -299,31 +490,78 @@ func (m Model) View() string {
 
 	if m.renaming {
 		inputStyle := m.styles.doc.
-			BorderStyle(lipgloss.RoundedBorder()).
+			BorderStyle(m.styles.border).
 			BorderForeground(m.styles.highlight).
 			Padding(1, 2).
 			MarginTop(m.config.Layout.HeaderGap)
 
-		prompt := "Enter folder name:\n\n" + m.textInput.View()
+		prompt := "Enter new name:\n\n" + m.textInput.View()
 		doc.WriteString(inputStyle.Render(prompt))
 		doc.WriteString("\n")
 		doc.WriteString(m.renderFooter())
 		return doc.String()
 	}
 
-	if len(m.notes) == 0 {
+	if m.pendingRename != nil {
+		preview := m.styles.doc.
+			BorderStyle(m.styles.border).
+			BorderForeground(m.styles.highlight).
+			Padding(1, 2).
+			MarginTop(m.config.Layout.HeaderGap).
+			Width(m.width - 2).
+			Render(m.renderRenamePreview(m.width-8, heights.Content-2))
+		doc.WriteString(preview)
+		doc.WriteString("\n")
+		doc.WriteString(m.renderFooter())
+		return doc.String()
+	}
+
+	if m.showMessages {
+		messageLog := m.styles.doc.
+			BorderStyle(m.styles.border).
+			BorderForeground(m.styles.highlight).
+			Padding(1, 2).
+			MarginTop(m.config.Layout.HeaderGap).
+			Width(m.width - 2).
+			Render(m.renderMessages(m.width-8, heights.Content-2))
+		doc.WriteString(messageLog)
+		doc.WriteString("\n")
+		doc.WriteString(m.renderFooter())
+		return doc.String()
+	}
+
+	if m.switching {
+		overlay := m.renderSwitcher(m.width, heights.Content+2)
+		doc.WriteString(lipgloss.NewStyle().MarginTop(m.config.Layout.HeaderGap).Render(overlay))
+		doc.WriteString("\n")
+		doc.WriteString(m.renderFooter())
+		return doc.String()
+	}
+
+	if len(m.notes) == 0 && !m.archiveView {
 		doc.WriteString(m.styles.doc.Render("No notes found. Press 'n' to create one."))
 	} else {
 		contentWidth := m.width - (paddingH * 2)
-		if m.showSidebar {
+		if m.showSidebar || m.searching || m.showBacklinks || m.tagView || m.archiveView {
 			sidebarWidth := m.config.Layout.SidebarWidth + (paddingH * 2)
 			contentWidth = m.width - sidebarWidth - (paddingH * 4)
 
+			sidebarContent := m.formatSidebarContent()
+			if m.searching {
+				sidebarContent = m.formatSearchResults(m.config.Layout.SidebarWidth, heights.Content)
+			} else if m.showBacklinks {
+				sidebarContent = m.formatBacklinks(m.config.Layout.SidebarWidth, heights.Content)
+			} else if m.tagView {
+				sidebarContent = m.formatTagSidebar(m.config.Layout.SidebarWidth, heights.Content)
+			} else if m.archiveView {
+				sidebarContent = m.formatArchive(m.config.Layout.SidebarWidth, heights.Content)
+			}
+
 			sidebar := m.styles.RenderSidebar(
 				m.config.Layout.SidebarWidth,
 				heights.Content,
 				m.config.Layout.HeaderGap,
-			)(m.formatSidebarContent())
+			)(sidebarContent)
 
 			content := m.renderContent(contentWidth, heights)
 			doc.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, sidebar, content))
@@ -340,19 +578,50 @@ func (m Model) View() string {
 func (m *Model) updatePreview() {
 	if len(m.notes) > 0 && m.cursor < len(m.notes) {
 		content, err := os.ReadFile(m.notes[m.cursor].path)
+		if err != nil && !m.notes[m.cursor].isDir {
+			m.notifyError("Could not read %s: %v", m.notes[m.cursor].path, err)
+		}
 		if err == nil {
 			m.notes[m.cursor].content = string(content)
-			rendered := m.renderMarkdown(string(content))
-			m.viewport.SetContent(rendered)
+			m.notes[m.cursor].meta, _ = parseFrontmatter(string(content))
+			m.links = extractLinks(string(content))
+			m.activeLink = -1
+			m.paragraph = nil
+			m.previewTarget = nil
+			m.renderPreview()
 			m.viewport.GotoTop()
-
-			// Extract wikilinks [[note]] positions in rendered text
-            m.links = extractLinks(string(content))
-            m.activeLink = 0
 		}
 	}
 }
 
+// renderPreview renders the current note with the active link or backlink
+// paragraph highlighted and returns the rendered line the highlight starts on.
+func (m *Model) renderPreview() int {
+	content := m.notes[m.cursor].content
+	highlight := lipgloss.NewStyle().Foreground(m.styles.highlight).Bold(true)
+	if m.activeLink >= 0 && m.activeLink < len(m.links) {
+		link := m.links[m.activeLink]
+		content = markSpan(content, link.Start, link.End)
+		highlight = highlight.Underline(true)
+	} else if m.paragraph != nil {
+		content = markSpan(content, m.paragraph.start, m.paragraph.end)
+	}
+	// Frontmatter is shown as a properties header instead of raw YAML
+	_, body := parseFrontmatter(content)
+	properties := m.renderProperties(m.notes[m.cursor].meta)
+	var rendered string
+	m.previewPending = false
+	if m.activeLink < 0 && m.paragraph == nil {
+		rendered = m.renderBody(body)
+	} else {
+		rendered = m.renderMarkdown(body)
+	}
+	var line int
+	m.preview, line = highlightMarked(properties+rendered, highlight)
+	m.viewport.SetContent(m.preview)
+	return line
+}
+
 func (m *Model) updateNotes() {
 	expandedFolders := make(map[string]bool)
 	for _, note := range m.notes {
@@ -363,8 +632,11 @@ func (m *Model) updateNotes() {
 
 	var walkNotes func(dir string, depth int) []Note
 	walkNotes = func(dir string, depth int) []Note {
-		var notes []Note
-		files, _ := os.ReadDir(dir)
+		var items []sortItem
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			m.notifyError("Could not list %s: %v", dir, err)
+		}
 		for _, f := range files {
 			path := filepath.Join(dir, f.Name())
 
@@ -373,36 +645,69 @@ func (m *Model) updateNotes() {
 				continue
 			}
 
+			info, err := f.Info()
+			if err != nil {
+				continue
+			}
+
 			if f.IsDir() {
-				folderNote := Note{
-					path:     path,
-					title:    f.Name(),
-					isDir:    true,
-					depth:    depth,
-					expanded: expandedFolders[path],
-				}
-				notes = append(notes, folderNote)
-				if folderNote.expanded {
-					notes = append(notes, walkNotes(path, depth+1)...)
-				}
+				items = append(items, sortItem{
+					note: Note{
+						path:     path,
+						title:    f.Name(),
+						isDir:    true,
+						depth:    depth,
+						expanded: expandedFolders[path],
+					},
+					name:    f.Name(),
+					modTime: info.ModTime(),
+					created: info.ModTime(),
+				})
 			} else if strings.HasSuffix(f.Name(), ".md") {
-				content, _ := os.ReadFile(path)
-				title := extractTitle(string(content))
-				if title == "" {
-					title = strings.TrimSuffix(f.Name(), ".md")
+				item := sortItem{
+					note: Note{
+						path:  path,
+						title: m.index.Title(path, info),
+						tags:  m.index.Tags(path),
+						depth: depth,
+					},
+					name:    f.Name(),
+					modTime: info.ModTime(),
+					created: info.ModTime(),
 				}
-				notes = append(notes, Note{
-					path:    path,
-					title:   title,
-					content: string(content),
-					depth:   depth,
-				})
+				if created := m.index.Created(path); !created.IsZero() {
+					item.created = created
+				}
+				items = append(items, item)
+			}
+		}
+
+		sortItems(items, m.config.SortOrderFor(dir))
+
+		var notes []Note
+		for _, item := range items {
+			notes = append(notes, item.note)
+			if item.note.isDir && item.note.expanded {
+				notes = append(notes, walkNotes(item.note.path, depth+1)...)
 			}
 		}
 		return notes
 	}
 
 	m.notes = walkNotes(".", 0)
+	m.saveIndex()
+}
+
+func (m *Model) saveIndex() {
+	if err := m.index.Save(); err != nil {
+		m.notifyError("Could not save the search index: %v", err)
+	}
+}
+
+func (m *Model) saveConfig() {
+	if err := SaveConfig(m.config); err != nil {
+		m.notifyError("Could not save the config: %v", err)
+	}
 }
 
 func extractTitle(content string) string {
@@ -415,33 +720,14 @@ func extractTitle(content string) string {
 	return ""
 }
 
-// Minimal extractor for [[wikilink]] style
-func extractLinks(s string) []Link {
-    var links []Link
-    offset := 0
-    for {
-        start := strings.Index(s[offset:], "[[")
-        if start == -1 {
-            break
-        }
-        start += offset
-        end := strings.Index(s[start:], "]]")
-        if end == -1 {
-            break
-        }
-        end += start + 2
-        target := s[start+2 : end-2]
-        links = append(links, Link{Start: start, End: end, Target: target})
-        offset = end
-    }
-    return links
-}
-
-func initialModel() (Model, error) {
+// initialModel sets up the TUI, restoring the last session unless fresh is
+// set.
+func initialModel(fresh bool) (Model, error) {
 	cfg, err := LoadConfig()
 	if err != nil {
 		return Model{}, err
 	}
+	purged, retentionErr := enforceRetention(cfg, time.Now(), false)
 
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
 	heights := cfg.CalculateHeights(height)
@@ -455,63 +741,81 @@ func initialModel() (Model, error) {
 	ti.CharLimit = 50
 	ti.Width = 30
 
-	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(width-(paddingH*2)-4),
-	)
+	si := textinput.New()
+	si.Placeholder = "Search notes"
+	si.Prompt = "/ "
+	si.Width = cfg.Layout.SidebarWidth - 4
+
+	pi := textinput.New()
+	pi.Placeholder = "Open note"
+	pi.Prompt = "> "
+
+	keys, err := NewKeyMap(cfg.Keys)
 	if err != nil {
+		return Model{}, err
+	}
+
+	themes, themeErrs := LoadThemes(cfg.ConfigDir)
+	theme := findTheme(themes, cfg.ActiveThemeName())
+	renderer, rendererErr := newMarkdownRenderer(theme, width-(paddingH*2)-4)
+	if rendererErr != nil {
 		renderer = nil // Will fallback to plain text
 	}
 
 	m := Model{
-		config:      cfg,
-		showSidebar: true,
-		viewport:    vp,
-		width:       width,
-		height:      height,
-		styles:      NewStyles(cfg),
-		textInput:   ti,
-		mdRenderer:  renderer,
+		config:        cfg,
+		showSidebar:   true,
+		viewport:      vp,
+		width:         width,
+		height:        height,
+		styles:        NewStyles(cfg, theme),
+		theme:         theme,
+		keys:          keys,
+		textInput:     ti,
+		searchInput:   si,
+		switcherInput: pi,
+		mdRenderer:    renderer,
+		activeLink:    -1,
+		tagExpanded:   make(map[string]bool),
+		previews:      newPreviewCache(),
 	}
 
-	os.MkdirAll(cfg.NotesDir, 0755)
-	os.Chdir(cfg.NotesDir)
-
-	m.updateNotes()
-	m.updatePreview()
-	return m, nil
-}
-
-func (m Model) formatSidebarContent() string {
-	var sidebarContent strings.Builder
-
-	for i, note := range m.notes {
-		style := lipgloss.NewStyle()
-		if i == m.cursor {
-			style = style.Foreground(m.styles.highlight)
-		}
+	if err := os.Chdir(cfg.NotesDir); err != nil {
+		return Model{}, fmt.Errorf("failed to open notes directory: %v", err)
+	}
 
-		indent := strings.Repeat("  ", note.depth)
-		var icon string
+	if m.debugLog, err = openDebugLog(cfg); err != nil {
+		m.notifyError("Could not open the debug log: %v", err)
+	}
+	for _, err := range themeErrs {
+		m.notifyError("Could not load %v", err)
+	}
+	if rendererErr != nil {
+		m.notifyError("Could not load glamour style %s: %v", theme.GlamourStyle, rendererErr)
+	}
+	for _, warning := range cfg.keyWarnings {
+		m.notify("Key %s", warning)
+	}
+	if retentionErr != nil {
+		m.notifyError("Archive retention failed: %v", retentionErr)
+	} else if len(purged) > 0 {
+		m.notify("Archive retention cleaned up %d items", len(purged))
+	}
 
-		if note.isDir {
-			if note.expanded {
-				icon = "▼ "
-			} else {
-				icon = "▶ "
-			}
-		} else {
-			if i < len(m.notes)-1 && m.notes[i+1].depth >= note.depth {
-				icon = "├─ "
-			} else {
-				icon = "└─ "
-			}
-		}
+	m.index = OpenIndex(filepath.Join(cfg.ConfigDir, "index.gob"), cfg.NotesDir)
+	m.journal = OpenJournal(filepath.Join(cfg.ConfigDir, "journal.yaml"), cfg.NotesDir)
+	m.index.Refresh(".", m.isArchiveDir)
+	if m.watcher, err = watchNotes(".", cfg.Watch, m.isArchiveDir); err != nil {
+		m.notifyError("File watcher: %v", err)
+	}
 
-		line := indent + icon + note.title
-		sidebarContent.WriteString(style.Render(line) + "\n")
+	m.updateNotes()
+	if session := LoadSession(sessionFile(cfg.ConfigDir), cfg.NotesDir); session != nil && !fresh {
+		m.restoreSession(session)
+	} else {
+		m.updatePreview()
 	}
-	return sidebarContent.String()
+	return m, nil
 }
 
 func (m Model) formatStatusBarContent() string {
@@ -519,27 +823,87 @@ func (m Model) formatStatusBarContent() string {
 	if m.cursor < len(m.notes) {
 		statusText = fmt.Sprintf("%s • %s", m.notes[m.cursor].title, statusText)
 	}
+	if n := len(m.index.LinkedFrom(m.currentNotePath())); n > 0 {
+		statusText = fmt.Sprintf("%s • %d backlinks", statusText, n)
+	}
+	statusText = fmt.Sprintf("%s • %s", statusText, m.config.SortOrderFor(m.getCurrentDirectory()))
 	return statusText
 }
 
 func (m Model) renderContent(width int, heights struct{ Content, Header, Footer int }) string {
+	content := m.viewport.View()
+	if m.editing {
+		content = m.renderInlineEditor()
+	}
 	return m.styles.RenderContent(
 		width,
 		heights.Content,
 		m.config.Layout.HeaderGap,
-	)(m.viewport.View())
+	)(content)
 }
 
 func (m Model) renderFooter() string {
+	if m.editing {
+		helpText := panelHelp(inputBindings(m.config.Keys),
+			"save", "clear: close", "toggle_checkbox", "live_preview", "quit")
+		return m.statusLine(m.inlineEditorStatus()) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
 	if m.renaming {
-		return m.styles.RenderStatusBar(m.width)("Enter to confirm • Esc to cancel")
+		return m.statusLine("Enter to confirm • Esc to cancel")
+	}
+
+	if m.pendingRename != nil {
+		return m.statusLine(panelHelp(m.config.Keys,
+			"open update_links: rename and update links", "skip_links", "up down: scroll", "clear cancel: cancel"))
+	}
+
+	if m.searching {
+		statusText := fmt.Sprintf("%d results", len(m.searchResults))
+		helpText := panelHelp(inputBindings(m.config.Keys), "up down prev_result next_result: select", "open: open", "clear: cancel")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
+	if m.showBacklinks {
+		statusText := fmt.Sprintf("%d backlinks", len(m.backlinks))
+		helpText := panelHelp(m.config.Keys, "up down: select", "open: open", "clear backlinks: close", "quit")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
+	if m.tagView {
+		statusText := fmt.Sprintf("%d tags", len(m.index.TagCounts()))
+		helpText := panelHelp(m.config.Keys,
+			"up down: up/down", "collapse expand: collapse/expand", "open: open", "tags clear: back to folders", "quit")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
+	if m.showMessages {
+		statusText := fmt.Sprintf("%d messages", len(m.messages))
+		helpText := panelHelp(m.config.Keys, "up down: scroll", "clear messages: close")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
+	if m.archiveView {
+		statusText := fmt.Sprintf("%d archived items", len(m.archiveEntries))
+		if m.confirmPurge {
+			statusText = fmt.Sprintf("Delete %s permanently? y/n", filepath.Base(m.archiveEntries[m.archiveCursor].Original))
+		}
+		helpText := panelHelp(m.config.Keys,
+			"up down: select", "open restore", "restore_here", "purge", "clear archive_view: close")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
+	}
+
+	if m.switching {
+		statusText := fmt.Sprintf("%d notes", len(m.switcherResults))
+		helpText := panelHelp(inputBindings(m.config.Keys), "up down prev_result next_result: select", "open: open", "clear: cancel")
+		return m.statusLine(statusText) + "\n" + m.styles.RenderStatusBar(m.width)(helpText)
 	}
 
 	statusText := m.formatStatusBarContent()
-	helpText := "↑/k,↓/j: up/down • h/l: expand • enter: edit • n: new note • N: new folder • backspace: archive • tab: show sidebar • q: quit"
+	helpText := keyHelp(m.config.Keys)
 
 	var footer strings.Builder
-	footer.WriteString(m.styles.RenderStatusBar(m.width)(statusText))
+	footer.WriteString(m.statusLine(statusText))
 	footer.WriteString("\n")
 	footer.WriteString(m.styles.RenderStatusBar(m.width)(helpText))
 
@@ -566,7 +930,7 @@ func (m Model) getCurrentDirectory() string {
 }
 
 func (m Model) isArchiveDir(path string) bool {
-	return path == m.config.ArchiveDir || filepath.Base(path) == "archive"
+	return m.config.isArchiveDir(path)
 }
 
 func (m *Model) renderMarkdown(content string) string {
@@ -574,7 +938,7 @@ func (m *Model) renderMarkdown(content string) string {
 		return content
 	}
 
-	rendered, err := m.mdRenderer.Render(content)
+	rendered, err := m.previews.render(m.mdRenderer, content)
 	if err != nil {
 		return content
 	}
@@ -583,11 +947,28 @@ func (m *Model) renderMarkdown(content string) string {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
 	// Add config flag alongside version flag
 	versionFlag := flag.Bool("version", false, "Print version information")
 	configFlag := flag.Bool("config", false, "Print configuration file location and contents")
+	freshFlag := flag.Bool("fresh", false, "Start without restoring the last session's folders, selection and scroll")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: note [-version] [-config] [-fresh]\n       note new|add|ls|cat|edit|mv|archive|search|export|today|journal [--json] ...")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	// Check if version flag was provided
 	if *versionFlag {
 		printVersion()
@@ -600,7 +981,7 @@ func main() {
 		os.Exit(0)
 	}
 
-	model, err := initialModel()
+	model, err := initialModel(*freshFlag)
 	if err != nil {
 		log.Fatalf("Failed to initialize model: %v", err)
 	}
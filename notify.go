@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// toastDuration is how long a notification stays in the status bar.
+const toastDuration = 4 * time.Second
+
+// maxMessages caps the message log.
+const maxMessages = 200
+
+type message struct {
+	text  string
+	isErr bool
+	time  time.Time
+}
+
+// clearToastMsg hides the toast with the given id once it has been shown
+// for toastDuration, unless a newer one replaced it.
+type clearToastMsg struct{ id int }
+
+// notify reports a successful operation.
+func (m *Model) notify(format string, args ...interface{}) {
+	m.addMessage(fmt.Sprintf(format, args...), false)
+}
+
+// notifyError reports a failed operation.
+func (m *Model) notifyError(format string, args ...interface{}) {
+	m.addMessage(fmt.Sprintf(format, args...), true)
+}
+
+func (m *Model) addMessage(text string, isErr bool) {
+	msg := message{text: text, isErr: isErr, time: time.Now()}
+	m.messages = append(m.messages, msg)
+	if len(m.messages) > maxMessages {
+		m.messages = m.messages[len(m.messages)-maxMessages:]
+	}
+	m.toast = &msg
+	m.toastID++
+
+	if m.debugLog != nil {
+		level := "INFO"
+		if isErr {
+			level = "ERROR"
+		}
+		m.debugLog.Printf("%s %s", level, text)
+	}
+}
+
+func (m Model) clearToastAfter() tea.Cmd {
+	id := m.toastID
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return clearToastMsg{id}
+	})
+}
+
+// openDebugLog opens ConfigDir/debug.log for appending when the debug_log
+// option is set.
+func openDebugLog(cfg *Config) (*log.Logger, error) {
+	if !cfg.DebugLog {
+		return nil, nil
+	}
+	f, err := os.OpenFile(filepath.Join(cfg.ConfigDir, "debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return log.New(f, "", log.LstdFlags), nil
+}
+
+// statusLine renders the first footer line, showing the current toast in
+// place of text while there is one.
+func (m Model) statusLine(text string) string {
+	if m.toast == nil {
+		return m.styles.RenderStatusBar(m.width)(text)
+	}
+	color := m.styles.highlight
+	if m.toast.isErr {
+		color = m.styles.errorText
+	}
+	toast := lipgloss.NewStyle().Foreground(color).Bold(true).Render(m.toast.text)
+	return m.styles.RenderStatusBar(m.width)(toast)
+}
+
+func (m Model) updateMessages(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys[msg.String()] {
+	case actionClear, actionMessages:
+		m.showMessages = false
+	case actionUp:
+		if m.messageScroll < len(m.messages)-1 {
+			m.messageScroll++
+		}
+	case actionDown:
+		if m.messageScroll > 0 {
+			m.messageScroll--
+		}
+	}
+	return m, nil
+}
+
+// renderMessages lists the message log, newest at the bottom. messageScroll
+// counts how many of the newest messages are scrolled out of view.
+func (m Model) renderMessages(width, height int) string {
+	if len(m.messages) == 0 {
+		return m.styles.muted.Render("No messages yet")
+	}
+
+	end := len(m.messages) - m.messageScroll
+	start := end - height
+	if start < 0 {
+		start = 0
+	}
+	var lines []string
+	for _, msg := range m.messages[start:end] {
+		style := lipgloss.NewStyle()
+		if msg.isErr {
+			style = style.Foreground(m.styles.errorText)
+		}
+		line := m.styles.muted.Render(msg.time.Format("15:04:05")) + " " + style.Render(ansi.Truncate(msg.text, width-9, "…"))
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNotifications(t *testing.T) {
+	keys, _ := NewKeyMap(DefaultKeys())
+	cfg := DefaultConfig()
+	cfg.ConfigDir = t.TempDir()
+	cfg.DebugLog = true
+	debugLog, err := openDebugLog(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Model{config: cfg, keys: keys, journal: &Journal{}, debugLog: debugLog}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	m = model.(Model)
+	if m.toast == nil || m.toast.text != "Nothing to undo" || m.toast.isErr {
+		t.Fatalf("toast = %+v, want the undo notice", m.toast)
+	}
+	if cmd == nil {
+		t.Errorf("a new toast should schedule its removal")
+	}
+
+	m.notifyError("Could not rename %s: %v", "a.md", os.ErrExist)
+	if len(m.messages) != 2 || !m.messages[1].isErr {
+		t.Errorf("messages = %+v", m.messages)
+	}
+
+	// A stale timer must not hide the newer toast
+	model, _ = m.Update(clearToastMsg{m.toastID - 1})
+	if m = model.(Model); m.toast == nil {
+		t.Errorf("stale clearToastMsg hid the current toast")
+	}
+	model, _ = m.Update(clearToastMsg{m.toastID})
+	if m = model.(Model); m.toast != nil {
+		t.Errorf("clearToastMsg did not hide the toast")
+	}
+
+	for i := 0; i < maxMessages+10; i++ {
+		m.notify("message %d", i)
+	}
+	if len(m.messages) != maxMessages {
+		t.Errorf("message log holds %d messages, want %d", len(m.messages), maxMessages)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(cfg.ConfigDir, "debug.log"))
+	if !strings.Contains(string(data), "INFO Nothing to undo") || !strings.Contains(string(data), "ERROR Could not rename a.md") {
+		t.Errorf("debug log = %q", data)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// mdLinkRe matches inline markdown links such as [text](../other.md#heading).
+var mdLinkRe = regexp.MustCompile(`\[[^\]]*\]\(([^)\s]+)\)`)
+
+// LinkEdit is a single link rewrite caused by a rename.
+type LinkEdit struct {
+	Path       string // note containing the link, before the rename
+	Line       int
+	Start, End int // byte span of the link target in the note
+	Old, New   string
+}
+
+// RenamePlan describes a rename and every link that has to change with it.
+type RenamePlan struct {
+	From, To string
+	Moves    map[string]string // note path before -> after the rename
+	Edits    []LinkEdit
+}
+
+// planRename works out which notes move when from is renamed to to and which
+// [[wikilinks]] and relative markdown links across the vault must be updated
+// so they keep pointing at the same notes.
+func (idx *Index) planRename(from, to string) *RenamePlan {
+	plan := &RenamePlan{From: from, To: to, Moves: make(map[string]string)}
+	if _, ok := idx.Entries[from]; ok {
+		plan.Moves[from] = to
+	}
+	prefix := from + string(filepath.Separator)
+	for path := range idx.Entries {
+		if strings.HasPrefix(path, prefix) {
+			plan.Moves[path] = filepath.Join(to, strings.TrimPrefix(path, prefix))
+		}
+	}
+	if len(plan.Moves) == 0 {
+		return plan
+	}
+
+	moved := func(path string) string {
+		if newPath, ok := plan.Moves[path]; ok {
+			return newPath
+		}
+		return path
+	}
+
+	paths := make([]string, 0, len(idx.Entries))
+	for path := range idx.Entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		entry := idx.Entries[path]
+		_, pathMoves := plan.Moves[path]
+		// Only notes with wikilinks, a ".md" token or that move themselves can need edits
+		if len(entry.Links) == 0 && !pathMoves && !hasTerm(entry, "md") {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		text := string(content)
+		newDir := filepath.Dir(moved(path))
+
+		for _, link := range extractLinks(text) {
+			target, kind := idx.resolveLink(link.Target, path)
+			if kind == linkUnresolved {
+				continue
+			}
+			_, targetMoves := plan.Moves[target]
+			if !targetMoves && !pathMoves {
+				continue
+			}
+
+			start := link.Start + 2 + strings.Index(text[link.Start+2:link.End], link.Target)
+			oldText := text[start : start+len(link.Target)]
+			// Keep any #heading that is part of the target
+			if i := strings.Index(oldText, "#"); i >= 0 {
+				oldText = oldText[:i]
+			}
+
+			newTarget := moved(target)
+			var replacement string
+			switch kind {
+			case linkRelative:
+				rel, err := filepath.Rel(newDir, newTarget)
+				if err != nil {
+					continue
+				}
+				replacement = filepath.ToSlash(rel)
+			case linkRoot:
+				replacement = filepath.ToSlash(newTarget)
+			case linkName:
+				if filepath.Base(target) == filepath.Base(newTarget) {
+					continue
+				}
+				replacement = filepath.Base(newTarget)
+			default:
+				// Titles and aliases come from the note content, which a rename keeps
+				continue
+			}
+			if !strings.HasSuffix(oldText, ".md") {
+				replacement = strings.TrimSuffix(replacement, ".md")
+			}
+			if replacement == oldText {
+				continue
+			}
+			plan.Edits = append(plan.Edits, LinkEdit{
+				Path:  path,
+				Line:  strings.Count(text[:start], "\n") + 1,
+				Start: start,
+				End:   start + len(oldText),
+				Old:   oldText,
+				New:   replacement,
+			})
+		}
+
+		for _, match := range mdLinkRe.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[2], match[3]
+			dest := text[start:end]
+			if i := strings.Index(dest, "#"); i >= 0 {
+				end = start + i
+				dest = dest[:i]
+			}
+			if !strings.HasSuffix(dest, ".md") || strings.Contains(dest, "://") || strings.HasPrefix(dest, "/") {
+				continue
+			}
+			target := filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.ReplaceAll(dest, "%20", " ")))
+			_, targetMoves := plan.Moves[target]
+			if !targetMoves && !pathMoves {
+				continue
+			}
+			if _, ok := idx.Entries[target]; !ok {
+				continue
+			}
+
+			rel, err := filepath.Rel(newDir, moved(target))
+			if err != nil {
+				continue
+			}
+			replacement := filepath.ToSlash(rel)
+			if strings.Contains(dest, "%20") {
+				replacement = strings.ReplaceAll(replacement, " ", "%20")
+			}
+			if replacement == dest {
+				continue
+			}
+			plan.Edits = append(plan.Edits, LinkEdit{
+				Path:  path,
+				Line:  strings.Count(text[:start], "\n") + 1,
+				Start: start,
+				End:   end,
+				Old:   dest,
+				New:   replacement,
+			})
+		}
+	}
+	return plan
+}
+
+func hasTerm(entry *IndexEntry, term string) bool {
+	for _, t := range entry.Terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply renames the file or folder and, when rewriteLinks is set, rewrites
+// every planned link edit. Each note is replaced atomically.
+func (p *RenamePlan) Apply(rewriteLinks bool) error {
+	if _, err := os.Stat(p.To); err == nil {
+		return fmt.Errorf("%s already exists", p.To)
+	}
+	created, err := makeParents(p.To)
+	if err == nil {
+		err = os.Rename(p.From, p.To)
+	}
+	if err != nil {
+		for _, dir := range created {
+			os.Remove(dir)
+		}
+		return err
+	}
+	if !rewriteLinks {
+		return nil
+	}
+
+	byPath := make(map[string][]LinkEdit)
+	for _, edit := range p.Edits {
+		byPath[edit.Path] = append(byPath[edit.Path], edit)
+	}
+	for path, edits := range byPath {
+		if newPath, ok := p.Moves[path]; ok {
+			path = newPath
+		}
+		if err := rewriteFile(path, edits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeParents creates the missing folders above path and returns them,
+// deepest first, so that they can be removed again if the move fails.
+func makeParents(path string) ([]string, error) {
+	var missing []string
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return missing, os.MkdirAll(missing[0], 0755)
+}
+
+func rewriteFile(path string, edits []LinkEdit) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+	text := string(content)
+	for _, edit := range edits {
+		if edit.End > len(text) || text[edit.Start:edit.End] != edit.Old {
+			return fmt.Errorf("%s changed since the rename was planned", path)
+		}
+		text = text[:edit.Start] + edit.New + text[edit.End:]
+	}
+	return writeFileAtomic(path, []byte(text))
+}
+
+// writeFileAtomic writes data next to path and renames it into place so
+// readers never see a partially written note.
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	mode := os.FileMode(0644)
+	if err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".note-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// startRename plans renaming the selected item to the typed name and either
+// applies it or shows the link changes for confirmation.
+func (m *Model) startRename() {
+	name := m.textInput.Value()
+	current := m.notes[m.cursor]
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		m.notifyError("Invalid name %q", name)
+		return
+	}
+	if !current.isDir && !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	newPath := filepath.Join(filepath.Dir(current.path), name)
+	if newPath == current.path {
+		return
+	}
+
+	m.index.Refresh(".", m.isArchiveDir)
+	plan := m.index.planRename(current.path, newPath)
+	if len(plan.Edits) > 0 && m.config.PreviewLinkUpdates {
+		m.pendingRename = plan
+		m.renameScroll = 0
+		return
+	}
+	m.applyRename(plan, true)
+}
+
+func (m *Model) applyRename(plan *RenamePlan, rewriteLinks bool) {
+	if err := plan.Apply(rewriteLinks); err != nil {
+		m.notifyError("Could not rename %s: %v", plan.From, err)
+	} else {
+		links := rewriteLinks && len(plan.Edits) > 0
+		m.record(Operation{Kind: opRename, Path: plan.From, To: plan.To, Links: links})
+		if links {
+			m.notify("Renamed %s to %s and updated %d links", plan.From, plan.To, len(plan.Edits))
+		} else {
+			m.notify("Renamed %s to %s", plan.From, plan.To)
+		}
+		m.index.Refresh(".", m.isArchiveDir)
+		m.updateNotes()
+		if !m.revealNote(plan.To) {
+			for i, note := range m.notes {
+				if note.path == plan.To {
+					m.cursor = i
+					break
+				}
+			}
+		}
+		m.updatePreview()
+	}
+}
+
+func (m Model) updateRenamePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.in(panelRename, msg.String()) {
+	case actionOpen, actionUpdateLinks:
+		m.applyRename(m.pendingRename, true)
+		m.pendingRename = nil
+	case actionSkipLinks:
+		m.applyRename(m.pendingRename, false)
+		m.pendingRename = nil
+	case actionClear, actionCancel:
+		m.pendingRename = nil
+	case actionUp:
+		if m.renameScroll > 0 {
+			m.renameScroll--
+		}
+	case actionDown:
+		if m.renameScroll < len(m.pendingRename.Edits)-1 {
+			m.renameScroll++
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderRenamePreview(width, height int) string {
+	plan := m.pendingRename
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Rename %s → %s", plan.From, plan.To)),
+		fmt.Sprintf("%d links in other notes will be updated:", len(plan.Edits)),
+		"",
+	}
+	for i := m.renameScroll; i < len(plan.Edits) && len(lines) < height; i++ {
+		edit := plan.Edits[i]
+		location := m.styles.muted.Render(fmt.Sprintf("%s:%d", edit.Path, edit.Line))
+		change := fmt.Sprintf("%s → %s", edit.Old, lipgloss.NewStyle().Foreground(m.styles.highlight).Render(edit.New))
+		lines = append(lines, ansi.Truncate(location+"  "+change, width, "…"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestRenamePlanRewritesLinks(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"projects/alpha.md": "# Alpha\n[[../inbox]] and [other](../inbox.md#top)\n",
+		"inbox.md":          "# Inbox\n[[alpha]], [[projects/alpha.md|Alpha]], [[Alpha#Goals]] and [a](projects/alpha.md)\n",
+		"other.md":          "# Other\n[[Alpha]] by title\n",
+	})
+
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(".", func(string) bool { return false })
+
+	plan := idx.planRename("projects", "work")
+	if len(plan.Moves) != 1 || plan.Moves[filepath.Join("projects", "alpha.md")] != filepath.Join("work", "alpha.md") {
+		t.Fatalf("planRename() moves = %v", plan.Moves)
+	}
+	if err := plan.Apply(true); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	tests := map[string]string{
+		"inbox.md":      "# Inbox\n[[alpha]], [[work/alpha.md|Alpha]], [[Alpha#Goals]] and [a](work/alpha.md)\n",
+		"work/alpha.md": "# Alpha\n[[../inbox]] and [other](../inbox.md#top)\n",
+		"other.md":      "# Other\n[[Alpha]] by title\n",
+	}
+	for path, want := range tests {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRenamePlanNoteName(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"todo.md":      "# Tasks\n",
+		"sub/daily.md": "See [[todo]], [[../todo#Later]] and [list](../todo.md).\n",
+	})
+
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(".", func(string) bool { return false })
+
+	plan := idx.planRename("todo.md", "tasks.md")
+	if len(plan.Edits) != 3 {
+		t.Fatalf("planRename() planned %d edits, want 3: %+v", len(plan.Edits), plan.Edits)
+	}
+	if err := plan.Apply(true); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join("sub", "daily.md"))
+	if want := "See [[tasks]], [[../tasks#Later]] and [list](../tasks.md).\n"; string(got) != want {
+		t.Errorf("sub/daily.md = %q, want %q", got, want)
+	}
+
+	if err := idx.planRename("sub/daily.md", "tasks.md").Apply(true); err == nil {
+		t.Errorf("Apply() should refuse to overwrite an existing note")
+	}
+
+	// Moving into new folders creates them, and a failed move removes them
+	if err := idx.planRename("tasks.md", filepath.Join("a", "b", "tasks.md")).Apply(false); err != nil {
+		t.Fatalf("Apply() into a new folder: %v", err)
+	}
+	if err := idx.planRename("missing.md", filepath.Join("c", "d", "missing.md")).Apply(false); err == nil {
+		t.Errorf("Apply() of a missing note should fail")
+	}
+	if _, err := os.Stat("c"); !os.IsNotExist(err) {
+		t.Errorf("a failed move left its new folders behind: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxRenderCache caps how many rendered notes are kept.
+const maxRenderCache = 100
+
+// renderKey identifies a rendering of a note: it is stale once the note is
+// modified or the preview is rewrapped to another width.
+type renderKey struct {
+	path    string
+	modTime int64
+	width   int
+}
+
+// renderJob is a note body waiting to be rendered in the background.
+type renderJob struct {
+	key  renderKey
+	body string
+	edit bool // the inline editor's live preview, which is not cached
+}
+
+// editPreviewDelay is how long typing must pause before the inline editor's
+// live preview is rendered again.
+const editPreviewDelay = 150 * time.Millisecond
+
+// previewTarget is where to scroll the preview: to the first line containing
+// text or, without text, to a line of the rendered note. It is kept until the
+// note is rendered, since the source shown meanwhile wraps differently.
+type previewTarget struct {
+	text   string
+	offset int
+}
+
+// previewRenderedMsg delivers the markdown rendered by a renderJob.
+type previewRenderedMsg struct {
+	key      renderKey
+	rendered string
+	edit     bool
+	err      error
+}
+
+// previewCache holds rendered notes and serializes use of the glamour
+// renderer, which is not safe for concurrent use. It is shared by pointer
+// between copies of the model.
+type previewCache struct {
+	mu       sync.Mutex
+	latest   atomic.Int64 // generation of the newest job; older jobs are skipped
+	rendered map[renderKey]string
+	order    []renderKey
+}
+
+func newPreviewCache() *previewCache {
+	return &previewCache{rendered: make(map[renderKey]string)}
+}
+
+func (c *previewCache) get(key renderKey) (string, bool) {
+	rendered, ok := c.rendered[key]
+	return rendered, ok
+}
+
+func (c *previewCache) put(key renderKey, rendered string) {
+	if _, ok := c.rendered[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.rendered[key] = rendered
+	if len(c.order) > maxRenderCache {
+		delete(c.rendered, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// clear drops every rendering, for when the glamour style changes.
+func (c *previewCache) clear() {
+	c.rendered = make(map[renderKey]string)
+	c.order = nil
+}
+
+// render runs renderer with the lock held. A nil renderer shows the
+// markdown as is.
+func (c *previewCache) render(renderer *glamour.TermRenderer, content string) (string, error) {
+	if renderer == nil {
+		return content, nil
+	}
+	if c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	return renderer.Render(content)
+}
+
+// renderLatest renders content for the job of the given generation, unless a
+// newer job was queued while it waited for the lock. Checking under the lock
+// keeps jobs queued behind a slow render from each rendering in turn.
+func (c *previewCache) renderLatest(generation int64, renderer *glamour.TermRenderer, content string) (rendered string, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latest.Load() != generation {
+		return "", false, nil
+	}
+	if renderer == nil {
+		return content, true, nil
+	}
+	rendered, err = renderer.Render(content)
+	return rendered, true, err
+}
+
+// renderKeyFor is the cache key of path at the current preview width.
+func (m Model) renderKeyFor(path string) renderKey {
+	key := renderKey{path: path, width: m.viewport.Width}
+	if info, err := os.Stat(path); err == nil {
+		key.modTime = info.ModTime().UnixNano()
+	}
+	return key
+}
+
+// renderBody returns the rendered body of the current note from the cache.
+// On a miss it queues a background render and returns the wrapped source
+// to show meanwhile; without a cache it renders in place.
+func (m *Model) renderBody(body string) string {
+	// The inline editor's preview rewraps the renderer to its own width
+	if m.previews == nil || m.editing {
+		return m.renderMarkdown(body)
+	}
+	key := m.renderKeyFor(m.notes[m.cursor].path)
+	if rendered, ok := m.previews.get(key); ok {
+		return rendered
+	}
+	m.pendingRender = &renderJob{key: key, body: body}
+	m.previewPending = true
+	return ansi.Wrap(body, m.viewport.Width-4, "")
+}
+
+// renderAsync renders job off the update loop. Jobs superseded by a newer
+// one before they get to render are dropped, so holding a key only renders
+// the note it stops on. Editor jobs wait for a pause in typing first.
+func (m Model) renderAsync(job renderJob) tea.Cmd {
+	previews, renderer := m.previews, m.mdRenderer
+	generation := previews.latest.Add(1)
+	return func() tea.Msg {
+		if job.edit {
+			time.Sleep(editPreviewDelay)
+		}
+		rendered, ok, err := previews.renderLatest(generation, renderer, job.body)
+		if !ok {
+			return nil
+		}
+		return previewRenderedMsg{key: job.key, rendered: rendered, edit: job.edit, err: err}
+	}
+}
+
+// previewRendered caches a finished render and shows it if the note it
+// belongs to is still on screen without a highlight.
+func (m *Model) previewRendered(msg previewRenderedMsg) {
+	if msg.err != nil {
+		m.notifyError("Could not render %s: %v", msg.key.path, msg.err)
+		return
+	}
+	if msg.edit {
+		if m.editing && m.editPreview {
+			meta, _ := parseFrontmatter(m.editor.Value())
+			m.editRendered = m.renderProperties(meta) + msg.rendered
+		}
+		return
+	}
+	m.previews.put(msg.key, msg.rendered)
+	if m.currentNotePath() != msg.key.path || m.activeLink >= 0 || m.paragraph != nil {
+		return
+	}
+	if m.renderKeyFor(msg.key.path) != msg.key {
+		return
+	}
+	target := m.previewTarget
+	m.renderPreview()
+	if target != nil {
+		m.scrollPreview(*target)
+	}
+}
+
+// scrollPreview scrolls the preview to target, and again once the note is
+// rendered if the source is shown until then.
+func (m *Model) scrollPreview(target previewTarget) {
+	m.previewTarget = nil
+	if m.previewPending {
+		m.previewTarget = &target
+	}
+	if target.text == "" {
+		m.viewport.SetYOffset(target.offset)
+		return
+	}
+	text := strings.ToLower(target.text)
+	for i, line := range strings.Split(ansi.Strip(m.preview), "\n") {
+		if strings.Contains(strings.ToLower(line), text) {
+			m.viewport.SetYOffset(i)
+			return
+		}
+	}
+}
+
+// previewOffset is the rendered line the preview is scrolled to, or the one
+// it will be once the note is rendered.
+func (m Model) previewOffset() int {
+	if m.previewTarget != nil && m.previewTarget.text == "" {
+		return m.previewTarget.offset
+	}
+	return m.viewport.YOffset
+}
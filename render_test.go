@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPreviewCache(t *testing.T) {
+	c := newPreviewCache()
+	for i := 0; i < maxRenderCache+5; i++ {
+		c.put(renderKey{path: fmt.Sprintf("%d.md", i), width: 80}, "rendered")
+	}
+	if _, ok := c.get(renderKey{path: "0.md", width: 80}); ok {
+		t.Errorf("oldest rendering should be evicted")
+	}
+	if _, ok := c.get(renderKey{path: fmt.Sprintf("%d.md", maxRenderCache+4), width: 80}); !ok {
+		t.Errorf("newest rendering should be cached")
+	}
+	if _, ok := c.get(renderKey{path: fmt.Sprintf("%d.md", maxRenderCache+4), width: 60}); ok {
+		t.Errorf("rendering at another width should miss")
+	}
+}
+
+func TestAsyncPreview(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"a.md": "# Alpha\n\nSome *text*.\n",
+		"b.md": "# Beta\n",
+	})
+	renderer, err := newMarkdownRenderer(builtinThemes[0], 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Model{
+		config:     DefaultConfig(),
+		index:      newIndex("", root),
+		viewport:   viewport.New(64, 20),
+		mdRenderer: renderer,
+		activeLink: -1,
+		previews:   newPreviewCache(),
+	}
+	m.updateNotes()
+	m.updatePreview()
+
+	job := m.pendingRender
+	if job == nil || job.key.path != "a.md" {
+		t.Fatalf("selecting a.md should queue a render, got %+v", job)
+	}
+	if !strings.Contains(m.preview, "*text*") {
+		t.Errorf("the source should show until the render is done, got %q", m.preview)
+	}
+
+	// While a slow render holds the renderer, a job already waiting for it
+	// is superseded by a newer one and never renders
+	m.previews.mu.Lock()
+	staleJob := *job
+	staleJob.body = "# Stale\n"
+	stale := m.renderAsync(staleJob)
+	staleMsg := make(chan tea.Msg)
+	go func() { staleMsg <- stale() }()
+	time.Sleep(20 * time.Millisecond)
+	cmd := m.renderAsync(*job)
+	freshMsg := make(chan tea.Msg)
+	go func() { freshMsg <- cmd() }()
+	m.previews.mu.Unlock()
+
+	if msg := <-staleMsg; msg != nil {
+		t.Errorf("superseded render returned %v", msg)
+	}
+	msg, ok := (<-freshMsg).(previewRenderedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("render returned %+v", msg)
+	}
+	m.pendingRender = nil
+	m.previewRendered(msg)
+	if strings.Contains(m.preview, "*text*") || !strings.Contains(m.preview, "Alpha") {
+		t.Errorf("preview was not replaced by the render: %q", m.preview)
+	}
+
+	// Coming back to the note uses the cache
+	m.cursor = 1
+	m.updatePreview()
+	m.cursor = 0
+	m.pendingRender = nil
+	m.updatePreview()
+	if m.pendingRender != nil {
+		t.Errorf("cached note should not be rendered again")
+	}
+
+	// Editing the note invalidates its rendering
+	future := time.Now().Add(time.Hour)
+	os.Chtimes("a.md", future, future)
+	m.updatePreview()
+	if m.pendingRender == nil {
+		t.Errorf("modified note should be rendered again")
+	}
+}
+
+func TestPreviewTargetWaitsForRender(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"a.md": "# Alpha\n\n" + strings.Repeat("- filler item\n\n", 60) + "Here is the needle.\n\n" + strings.Repeat("- filler item\n\n", 20),
+	})
+	renderer, err := newMarkdownRenderer(builtinThemes[0], 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Model{
+		config:     DefaultConfig(),
+		index:      newIndex("", root),
+		viewport:   viewport.New(64, 10),
+		mdRenderer: renderer,
+		activeLink: -1,
+		previews:   newPreviewCache(),
+	}
+	m.updateNotes()
+	m.updatePreview()
+	m.scrollPreview(previewTarget{text: "needle"})
+	if m.previewTarget == nil {
+		t.Fatalf("target should be kept until the note is rendered")
+	}
+
+	msg := m.renderAsync(*m.pendingRender)().(previewRenderedMsg)
+	m.pendingRender = nil
+	m.previewRendered(msg)
+	want := -1
+	for i, line := range strings.Split(ansi.Strip(m.preview), "\n") {
+		if strings.Contains(line, "needle") {
+			want = i
+			break
+		}
+	}
+	if want < 0 || m.viewport.YOffset != want {
+		t.Errorf("preview offset = %d, want the rendered needle line %d", m.viewport.YOffset, want)
+	}
+	if m.previewTarget != nil {
+		t.Errorf("target should be dropped once applied")
+	}
+
+	// An offset restored before the render is saved as is
+	m.previews.clear()
+	m.updatePreview()
+	m.scrollPreview(previewTarget{offset: 12})
+	if got := m.previewOffset(); got != 12 {
+		t.Errorf("previewOffset() = %d while rendering, want 12", got)
+	}
+}
+
+func TestAsyncEditPreview(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{"a.md": "# Alpha\n"})
+	renderer, err := newMarkdownRenderer(builtinThemes[0], 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Model{
+		config:      DefaultConfig(),
+		index:       newIndex("", root),
+		viewport:    viewport.New(80, 20),
+		mdRenderer:  renderer,
+		activeLink:  -1,
+		previews:    newPreviewCache(),
+		editPreview: true,
+	}
+	m.updateNotes()
+	m.startInlineEdit()
+	if m.pendingRender == nil || !m.pendingRender.edit || !strings.Contains(m.editRendered, "# Alpha") {
+		t.Fatalf("opening the editor should show the source and queue a render, got %q", m.editRendered)
+	}
+
+	// Only the render after the last keystroke runs
+	typed := m.renderAsync(*m.pendingRender)
+	m.editor.InsertString("Some *text*.")
+	m.refreshEditPreview()
+	last := m.renderAsync(*m.pendingRender)
+	m.pendingRender = nil
+	if msg := typed(); msg != nil {
+		t.Errorf("superseded editor render returned %v", msg)
+	}
+	msg := last().(previewRenderedMsg)
+	m.previewRendered(msg)
+	if !strings.Contains(m.editRendered, "text") || strings.Contains(m.editRendered, "*text*") {
+		t.Errorf("live preview = %q", m.editRendered)
+	}
+	if _, ok := m.previews.get(msg.key); ok {
+		t.Errorf("editor renders should not be cached")
+	}
+}
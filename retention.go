@@ -0,0 +1,209 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Retention limits how much the archive keeps. Zero disables a limit. The
+// policy is enforced at startup and by `note archive purge`.
+type Retention struct {
+	MaxAgeDays int `yaml:"max_age_days"`
+	MaxSizeMB  int `yaml:"max_size_mb"`
+	MaxCount   int `yaml:"max_count"`
+	// Items older than this are packed into a dated zip in the archive dir
+	// instead of being kept as loose files
+	CompressAfterDays int `yaml:"compress_after_days"`
+}
+
+func (r Retention) enabled() bool {
+	return r.MaxAgeDays > 0 || r.MaxSizeMB > 0 || r.MaxCount > 0 || r.CompressAfterDays > 0
+}
+
+// retentionAction is a single step of enforcing the retention policy.
+type retentionAction struct {
+	Entry    ArchiveEntry
+	Compress bool // compress instead of delete
+	Reason   string
+}
+
+func retentionDays(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+func isArchiveZip(entry ArchiveEntry) bool {
+	return !entry.IsDir && strings.HasPrefix(entry.Name, "archive-") && strings.HasSuffix(entry.Name, ".zip")
+}
+
+// planRetention works out what the policy removes from entries, which are
+// sorted newest first. Expired items go first, then the oldest items beyond
+// the count and size limits; what is left and old enough gets compressed.
+func planRetention(entries []ArchiveEntry, r Retention, now time.Time) []retentionAction {
+	var actions []retentionAction
+	var kept []ArchiveEntry
+	for _, entry := range entries {
+		if r.MaxAgeDays > 0 && now.Sub(entry.ArchivedAt) > retentionDays(r.MaxAgeDays) {
+			actions = append(actions, retentionAction{Entry: entry, Reason: fmt.Sprintf("older than %d days", r.MaxAgeDays)})
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	var count int
+	var size int64
+	var compress []ArchiveEntry
+	for i, entry := range kept {
+		count++
+		size += entry.Size
+		if r.MaxCount > 0 && count > r.MaxCount {
+			for _, rest := range kept[i:] {
+				actions = append(actions, retentionAction{Entry: rest, Reason: fmt.Sprintf("more than %d items", r.MaxCount)})
+			}
+			break
+		}
+		if r.MaxSizeMB > 0 && size > int64(r.MaxSizeMB)<<20 {
+			for _, rest := range kept[i:] {
+				actions = append(actions, retentionAction{Entry: rest, Reason: fmt.Sprintf("archive over %d MB", r.MaxSizeMB)})
+			}
+			break
+		}
+		if r.CompressAfterDays > 0 && now.Sub(entry.ArchivedAt) > retentionDays(r.CompressAfterDays) && !isArchiveZip(entry) {
+			compress = append(compress, entry)
+		}
+	}
+
+	for _, entry := range compress {
+		actions = append(actions, retentionAction{Entry: entry, Compress: true, Reason: fmt.Sprintf("older than %d days", r.CompressAfterDays)})
+	}
+	return actions
+}
+
+// enforceRetention applies the retention policy to the archive dir. With
+// dryRun set it only reports what would happen.
+func enforceRetention(cfg *Config, now time.Time, dryRun bool) ([]retentionAction, error) {
+	if !cfg.ArchiveRetention.enabled() {
+		return nil, nil
+	}
+	// Zips count towards the limits, and expire, like the items they hold
+	entries, err := listArchiveDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	actions := planRetention(entries, cfg.ArchiveRetention, now)
+	if dryRun {
+		return actions, nil
+	}
+
+	var compress []ArchiveEntry
+	for _, action := range actions {
+		if action.Compress {
+			compress = append(compress, action.Entry)
+		} else if err := purgeArchived(cfg, action.Entry); err != nil {
+			return actions, err
+		}
+	}
+	if len(compress) > 0 {
+		if _, err := compressArchived(cfg, compress, now); err != nil {
+			return actions, err
+		}
+	}
+	return actions, nil
+}
+
+// compressArchived packs entries into archive-YYYY-MM-DD.zip, together with
+// their manifest records, removes the originals and returns the zip's path.
+func compressArchived(cfg *Config, entries []ArchiveEntry, now time.Time) (string, error) {
+	name := "archive-" + now.Format("2006-01-02")
+	path := filepath.Join(cfg.ArchiveDir, name+".zip")
+	for counter := 2; ; counter++ {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(cfg.ArchiveDir, fmt.Sprintf("%s-%d.zip", name, counter))
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	zw := zip.NewWriter(f)
+	err = writeZip(zw, cfg.ArchiveDir, entries)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if err := purgeArchived(cfg, entry); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+func writeZip(zw *zip.Writer, dir string, entries []ArchiveEntry) error {
+	for _, entry := range entries {
+		root := filepath.Join(dir, entry.Name)
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(rel)
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			src, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			_, err = io.Copy(w, src)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	manifest, err := yaml.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create("manifest.yaml")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(manifest)
+	return err
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(name string, age int, size int64) ArchiveEntry {
+		return ArchiveEntry{Name: name, ArchivedAt: now.Add(-retentionDays(age)), Size: size}
+	}
+	// Newest first, as ListArchive returns them
+	entries := []ArchiveEntry{
+		entry("a.md", 1, 400<<10),
+		entry("b.md", 10, 400<<10),
+		entry("archive-2024-01-01.zip", 40, 400<<10),
+		entry("c.md", 50, 400<<10),
+		entry("d.md", 100, 400<<10),
+	}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		want      []string // "delete name" or "compress name"
+	}{
+		{"no limits", Retention{}, nil},
+		{"max age", Retention{MaxAgeDays: 60}, []string{"delete d.md"}},
+		{"max count", Retention{MaxCount: 3}, []string{"delete c.md", "delete d.md"}},
+		{"max size", Retention{MaxSizeMB: 1}, []string{"delete archive-2024-01-01.zip", "delete c.md", "delete d.md"}},
+		{"compress", Retention{CompressAfterDays: 7}, []string{"compress b.md", "compress c.md", "compress d.md"}},
+		{"age before compress", Retention{MaxAgeDays: 60, CompressAfterDays: 30}, []string{"delete d.md", "compress c.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, action := range planRetention(entries, tt.retention, now) {
+				verb := "delete "
+				if action.Compress {
+					verb = "compress "
+				}
+				got = append(got, verb+action.Entry.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforceRetention(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"old.md":          "# Old\n",
+		"folder/inner.md": "# Inner\n",
+		"new.md":          "# New\n",
+	})
+	cfg := DefaultConfig()
+	cfg.NotesDir = root
+	cfg.ArchiveDir = filepath.Join(root, "archive")
+	cfg.ArchiveRetention = Retention{CompressAfterDays: 30}
+	for _, path := range []string{"old.md", "folder", "new.md"} {
+		if _, err := archiveNote(cfg, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Enforce as if the newest note was archived yesterday and the rest long ago
+	entries, _ := ListArchive(cfg)
+	var newest time.Time
+	for _, entry := range entries {
+		if entry.Original == "new.md" {
+			newest = entry.ArchivedAt
+		}
+	}
+	updateManifest(cfg, func(manifest map[string]ArchiveEntry) {
+		for name, entry := range manifest {
+			if entry.Original != "new.md" {
+				entry.ArchivedAt = newest.Add(-retentionDays(90))
+				manifest[name] = entry
+			}
+		}
+	})
+	now := newest.Add(retentionDays(1))
+
+	planned, err := enforceRetention(cfg, now, true)
+	if err != nil || len(planned) != 2 {
+		t.Fatalf("dry run = %+v, %v; want 2 compressions", planned, err)
+	}
+	if entries, _ := ListArchive(cfg); len(entries) != 3 {
+		t.Fatalf("dry run changed the archive: %+v", entries)
+	}
+
+	if _, err := enforceRetention(cfg, now, false); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = listArchiveDir(cfg)
+	if len(entries) != 2 {
+		t.Fatalf("archive after compressing = %+v, want new.md and a zip", entries)
+	}
+	if entries, _ = ListArchive(cfg); len(entries) != 1 || isArchiveZip(entries[0]) {
+		t.Errorf("ListArchive() = %+v, want only new.md to be restorable", entries)
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(cfg.ArchiveDir, "archive-"+now.Format("2006-01-02")+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, filepath.Base(f.Name))
+	}
+	if len(names) != 3 || names[len(names)-1] != "manifest.yaml" {
+		t.Errorf("zip contains %v, want both items and the manifest", names)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ArchiveDir, archiveManifest)); err != nil {
+		t.Errorf("manifest missing: %v", err)
+	}
+}
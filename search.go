@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxSnippets caps how many matching lines are shown per search result.
+const maxSnippets = 3
+
+type Snippet struct {
+	Line int // 1-based line number in the note
+	Text string
+}
+
+type SearchResult struct {
+	Path     string
+	Title    string
+	Score    int
+	Snippets []Snippet
+}
+
+// maxResults caps how many ranked results are loaded for snippets.
+const maxResults = 100
+
+// Search ranks indexed notes against the query. Every term must occur in the
+// note, either as part of an indexed word or in its title; title hits weigh
+// more than body hits.
+func (idx *Index) Search(query string) []SearchResult {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var scores map[string]int
+	for _, term := range terms {
+		hits := make(map[string]int)
+		for token, postings := range idx.Postings {
+			if !strings.Contains(token, term) {
+				continue
+			}
+			for path, count := range postings {
+				hits[path] += count
+			}
+		}
+		for path, entry := range idx.Entries {
+			if n := strings.Count(strings.ToLower(entry.Title), term); n > 0 {
+				hits[path] += n * 10
+			}
+		}
+
+		if scores == nil {
+			scores = hits
+			continue
+		}
+		for path, score := range scores {
+			if hits[path] == 0 {
+				delete(scores, path)
+			} else {
+				scores[path] = score + hits[path]
+			}
+		}
+	}
+
+	var results []SearchResult
+	for path, score := range scores {
+		results = append(results, SearchResult{
+			Path:  path,
+			Title: idx.Entries[path].Title,
+			Score: score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+
+	// Only the ranked results are read back from disk for their snippets
+	for i := range results {
+		content, err := os.ReadFile(results[i].Path)
+		if err == nil {
+			results[i].Snippets = matchingLines(string(content), terms)
+		}
+	}
+	return results
+}
+
+func matchingLines(content string, terms []string) []Snippet {
+	var snippets []Snippet
+	for i, line := range strings.Split(content, "\n") {
+		lower := strings.ToLower(line)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				snippets = append(snippets, Snippet{Line: i + 1, Text: strings.TrimSpace(line)})
+				break
+			}
+		}
+		if len(snippets) == maxSnippets {
+			break
+		}
+	}
+	return snippets
+}
+
+func (m *Model) runSearch() {
+	m.searchResults = m.index.Search(m.searchInput.Value())
+	m.searchCursor = 0
+}
+
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.inInput(panelPicker, msg) {
+	case actionClear:
+		m.searching = false
+		m.searchInput.Blur()
+		return m, nil
+	case actionOpen:
+		if m.searchCursor < len(m.searchResults) {
+			result := m.searchResults[m.searchCursor]
+			if m.revealNote(result.Path) {
+				m.updatePreview()
+				if terms := tokenize(m.searchInput.Value()); len(terms) > 0 {
+					m.scrollPreview(previewTarget{text: terms[0]})
+				}
+			}
+		}
+		m.searching = false
+		m.searchInput.Blur()
+		return m, nil
+	case actionUp, actionPrevResult:
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+		return m, nil
+	case actionDown, actionNextResult:
+		if m.searchCursor < len(m.searchResults)-1 {
+			m.searchCursor++
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	query := m.searchInput.Value()
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != query {
+		m.runSearch()
+	}
+	return m, cmd
+}
+
+// revealNote expands every folder above path and moves the cursor onto it.
+func (m *Model) revealNote(path string) bool {
+	dir := filepath.Dir(path)
+	var ancestors []string
+	for dir != "." && dir != string(filepath.Separator) {
+		ancestors = append([]string{dir}, ancestors...)
+		dir = filepath.Dir(dir)
+	}
+
+	for _, ancestor := range ancestors {
+		for i, note := range m.notes {
+			if note.isDir && note.path == ancestor {
+				if !note.expanded {
+					m.notes[i].expanded = true
+					m.updateNotes()
+				}
+				break
+			}
+		}
+	}
+
+	for i, note := range m.notes {
+		if note.path == path {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+func (m Model) formatSearchResults(width, height int) string {
+	var lines []string
+	lines = append(lines, m.searchInput.View(), "")
+
+	var blocks [][]string
+	for i, result := range m.searchResults {
+		style := lipgloss.NewStyle()
+		if i == m.searchCursor {
+			style = style.Foreground(m.styles.highlight).Bold(true)
+		}
+		block := []string{style.Render(ansi.Truncate(result.Title, width, "…"))}
+		for _, snippet := range result.Snippets {
+			text := fmt.Sprintf("  %d: %s", snippet.Line, snippet.Text)
+			block = append(block, m.styles.muted.Render(ansi.Truncate(text, width, "…")))
+		}
+		blocks = append(blocks, block)
+	}
+
+	// Drop leading results until the selected one fits in the pane
+	available := height - len(lines)
+	start := 0
+	for start < m.searchCursor {
+		used := 0
+		for _, block := range blocks[start : m.searchCursor+1] {
+			used += len(block)
+		}
+		if used <= available {
+			break
+		}
+		start++
+	}
+
+	for _, block := range blocks[start:] {
+		if len(lines)+len(block) > height {
+			break
+		}
+		lines = append(lines, block...)
+	}
+
+	if m.searchInput.Value() != "" && len(m.searchResults) == 0 {
+		lines = append(lines, "No matches")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeNotes(t *testing.T, root string, notes map[string]string) {
+	t.Helper()
+	for path, content := range notes {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		"a.md":           "# Groceries\nmilk\neggs",
+		"b.md":           "# Milk facts\nmilk is white\nmilk comes from cows",
+		"deep/c.md":      "# Unrelated\nnothing here",
+		"archive/d.md":   "# Archived milk\n",
+		"not-a-note.txt": "milk",
+	})
+
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(root, func(path string) bool { return filepath.Base(path) == "archive" })
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "title matches rank first",
+			query:    "milk",
+			expected: []string{"b.md", "a.md"},
+		},
+		{
+			name:     "all terms must match",
+			query:    "milk eggs",
+			expected: []string{"a.md"},
+		},
+		{
+			name:     "matching is case insensitive and includes nested notes",
+			query:    "NOTHING",
+			expected: []string{"deep/c.md"},
+		},
+		{
+			name:     "terms match inside words",
+			query:    "ocerie",
+			expected: []string{"a.md"},
+		},
+		{
+			name:     "empty query returns nothing",
+			query:    "  ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search(tt.query)
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Search() returned %d results, want %d", len(results), len(tt.expected))
+			}
+			for i, result := range results {
+				if result.Path != filepath.Join(root, tt.expected[i]) {
+					t.Errorf("result %d = %v, want %v", i, result.Path, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingLines(t *testing.T) {
+	got := matchingLines("one\n  two match\nthree\nmatch four\nmatch\nmatch", []string{"match"})
+	want := []Snippet{{Line: 2, Text: "two match"}, {Line: 4, Text: "match four"}, {Line: 5, Text: "match"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d snippets, want %d", len(got), len(want))
+	}
+	for i, snippet := range got {
+		if snippet != want[i] {
+			t.Errorf("snippet %d = %+v, want %+v", i, snippet, want[i])
+		}
+	}
+}
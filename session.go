@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Session is the state of the TUI that is restored on the next start.
+type Session struct {
+	Root          string   `yaml:"root"`
+	Expanded      []string `yaml:"expanded"`
+	Selected      string   `yaml:"selected"`
+	ShowSidebar   bool     `yaml:"show_sidebar"`
+	SidebarOffset int      `yaml:"sidebar_offset"`
+	PreviewOffset int      `yaml:"preview_offset"`
+}
+
+// sessionFile is where the session is kept, in the config dir next to the
+// index rather than in the notes dir, which may be synced.
+func sessionFile(configDir string) string {
+	return filepath.Join(configDir, "session.yaml")
+}
+
+// LoadSession reads the session saved in file. It returns nil when there is
+// none, it cannot be read or it was saved for a different notes directory.
+func LoadSession(file, root string) *Session {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	s := &Session{}
+	if yaml.Unmarshal(data, s) != nil || s.Root != root {
+		return nil
+	}
+	return s
+}
+
+func (s *Session) Save(file string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data)
+}
+
+// session captures the current state of the model.
+func (m Model) session() *Session {
+	s := &Session{
+		Root:          m.config.NotesDir,
+		ShowSidebar:   m.showSidebar,
+		SidebarOffset: m.sidebarOffset,
+		PreviewOffset: m.previewOffset(),
+	}
+	for _, note := range m.notes {
+		if note.isDir && note.expanded {
+			s.Expanded = append(s.Expanded, note.path)
+		}
+	}
+	if m.cursor < len(m.notes) {
+		s.Selected = m.notes[m.cursor].path
+	}
+	return s
+}
+
+func (m *Model) saveSession() {
+	if err := m.session().Save(sessionFile(m.config.ConfigDir)); err != nil {
+		m.notifyError("Could not save the session: %v", err)
+	}
+}
+
+// restoreSession expands the folders, selects the note and scrolls the
+// views as they were in s. Folders and notes that are gone are skipped.
+func (m *Model) restoreSession(s *Session) {
+	m.showSidebar = s.ShowSidebar
+
+	// Parents first, so that their children are in the tree when reached
+	expanded := append([]string(nil), s.Expanded...)
+	sort.Slice(expanded, func(i, j int) bool {
+		return strings.Count(expanded[i], string(filepath.Separator)) < strings.Count(expanded[j], string(filepath.Separator))
+	})
+	for _, path := range expanded {
+		for i, note := range m.notes {
+			if note.isDir && note.path == path {
+				m.notes[i].expanded = true
+				m.updateNotes()
+				break
+			}
+		}
+	}
+
+	if s.Selected != "" {
+		m.revealNote(s.Selected)
+	}
+	m.sidebarOffset = s.SidebarOffset
+	m.scrollSidebar()
+	m.updatePreview()
+	m.scrollPreview(previewTarget{offset: s.PreviewOffset})
+}
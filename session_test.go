@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+func TestSession(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	long := "# Long\n" + strings.Repeat("line\n", 50)
+	writeNotes(t, root, map[string]string{
+		"a.md":              "# A\n",
+		"work/b.md":         "# B\n",
+		"work/deep/long.md": long,
+		"other/c.md":        "# C\n",
+	})
+	cfg := DefaultConfig()
+	cfg.NotesDir = root
+	newModel := func() Model {
+		m := Model{
+			config:      cfg,
+			index:       newIndex("", root),
+			viewport:    viewport.New(80, 10),
+			showSidebar: true,
+			height:      20,
+		}
+		m.updateNotes()
+		return m
+	}
+
+	m := newModel()
+	m.revealNote(filepath.Join("work", "deep", "long.md"))
+	m.updatePreview()
+	m.viewport.SetYOffset(7)
+	m.showSidebar = false
+
+	file := filepath.Join(t.TempDir(), "session.yaml")
+	if err := m.session().Save(file); err != nil {
+		t.Fatal(err)
+	}
+	if LoadSession(file, "/elsewhere") != nil {
+		t.Errorf("a session saved for another notes directory should be ignored")
+	}
+	s := LoadSession(file, root)
+	if s == nil {
+		t.Fatal("LoadSession() = nil")
+	}
+
+	restored := newModel()
+	restored.restoreSession(s)
+	if got := restored.notes[restored.cursor].path; got != filepath.Join("work", "deep", "long.md") {
+		t.Errorf("selected %s after restoring", got)
+	}
+	var expanded []string
+	for _, note := range restored.notes {
+		if note.expanded {
+			expanded = append(expanded, note.path)
+		}
+	}
+	if strings.Join(expanded, ",") != strings.Join(s.Expanded, ",") || len(expanded) != 2 {
+		t.Errorf("expanded folders = %v, want %v", expanded, s.Expanded)
+	}
+	if restored.showSidebar || restored.viewport.YOffset != 7 {
+		t.Errorf("showSidebar = %v, preview offset = %d", restored.showSidebar, restored.viewport.YOffset)
+	}
+
+	// Notes that are gone are skipped
+	s.Selected = "missing.md"
+	s.Expanded = append(s.Expanded, "missing")
+	other := newModel()
+	other.restoreSession(s)
+	if other.cursor != 0 {
+		t.Errorf("cursor = %d, want the first note", other.cursor)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sidebarSize is the room for text inside the sidebar's padding.
+func (m Model) sidebarSize() (width, rows int) {
+	paddingH, paddingV := m.config.GetPadding()
+	width = m.config.Layout.SidebarWidth - 2*paddingH
+	rows = m.config.CalculateHeights(m.height).Content - 2*paddingV
+	if width < 1 {
+		width = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return width, rows
+}
+
+// scrollWindow returns the first of rows visible items out of total, moving
+// offset no further than needed to keep cursor in view.
+func scrollWindow(cursor, offset, rows, total int) int {
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+rows {
+		offset = cursor - rows + 1
+	}
+	if offset > total-rows {
+		offset = total - rows
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// scrollSidebar keeps the selected note in view. The Update wrapper calls it
+// after every message, wherever the cursor was moved.
+func (m *Model) scrollSidebar() {
+	_, rows := m.sidebarSize()
+	m.sidebarOffset = scrollWindow(m.cursor, m.sidebarOffset, rows, len(m.notes))
+}
+
+// scrollbar reports which of the visible rows the scrollbar thumb covers. It
+// is sized and placed by how much of the list is shown and where.
+func scrollbar(offset, rows, total int) []bool {
+	thumb := rows * rows / total
+	if thumb < 1 {
+		thumb = 1
+	}
+	start := 0
+	if total > rows {
+		start = offset * (rows - thumb) / (total - rows)
+	}
+	bar := make([]bool, rows)
+	for i := start; i < start+thumb && i < rows; i++ {
+		bar[i] = true
+	}
+	return bar
+}
+
+// formatSidebarContent renders the rows of the tree that fit in the sidebar,
+// with titles truncated to its width and a scrollbar when the tree is
+// taller than the sidebar.
+func (m Model) formatSidebarContent() string {
+	width, rows := m.sidebarSize()
+	offset := scrollWindow(m.cursor, m.sidebarOffset, rows, len(m.notes))
+	end := offset + rows
+	if end > len(m.notes) {
+		end = len(m.notes)
+	}
+
+	var bar []bool
+	if len(m.notes) > rows {
+		bar = scrollbar(offset, rows, len(m.notes))
+		// A sidebar too narrow for titles still shows the scrollbar
+		if width -= 2; width < 1 {
+			width = 1
+		}
+	}
+
+	var lines []string
+	for i := offset; i < end; i++ {
+		note := m.notes[i]
+		style := lipgloss.NewStyle()
+		if i == m.cursor {
+			style = style.Foreground(m.styles.highlight)
+		}
+
+		indent := strings.Repeat("  ", note.depth)
+		var icon string
+
+		if note.isDir {
+			if note.expanded {
+				icon = "▼ "
+			} else {
+				icon = "▶ "
+			}
+		} else {
+			if i < len(m.notes)-1 && m.notes[i+1].depth >= note.depth {
+				icon = "├─ "
+			} else {
+				icon = "└─ "
+			}
+		}
+
+		line := ansi.Truncate(indent+icon+note.title, width, "…")
+		if bar != nil {
+			track := m.styles.muted.Render("│")
+			if bar[i-offset] {
+				track = lipgloss.NewStyle().Foreground(m.styles.highlight).Render("┃")
+			}
+			// Pad by display width so the scrollbar lines up
+			line += strings.Repeat(" ", width-ansi.StringWidth(line)+1)
+			lines = append(lines, style.Render(line)+track)
+			continue
+		}
+		lines = append(lines, style.Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestScrollWindow(t *testing.T) {
+	tests := []struct {
+		name                        string
+		cursor, offset, rows, total int
+		want                        int
+	}{
+		{"fits", 3, 0, 10, 5, 0},
+		{"cursor in view", 12, 5, 10, 50, 5},
+		{"cursor below", 20, 5, 10, 50, 11},
+		{"cursor above", 2, 5, 10, 50, 2},
+		{"list shrank", 9, 40, 10, 12, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrollWindow(tt.cursor, tt.offset, tt.rows, tt.total); got != tt.want {
+				t.Errorf("scrollWindow() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSidebarScrolls(t *testing.T) {
+	cfg := DefaultConfig()
+	m := Model{config: cfg, height: 20}
+	for i := 0; i < 100; i++ {
+		m.notes = append(m.notes, Note{path: fmt.Sprintf("%02d.md", i), title: fmt.Sprintf("Note %02d with a title far too long for the sidebar", i)})
+	}
+	width, rows := m.sidebarSize()
+
+	m.cursor = 60
+	m.scrollSidebar()
+	lines := strings.Split(m.formatSidebarContent(), "\n")
+	if len(lines) != rows {
+		t.Fatalf("rendered %d lines, want %d", len(lines), rows)
+	}
+	last := ansi.Strip(lines[len(lines)-1])
+	if !strings.Contains(last, "Note 60") {
+		t.Errorf("last line = %q, want the selected note", last)
+	}
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w != width {
+			t.Errorf("line %q is %d wide, want %d", ansi.Strip(line), w, width)
+		}
+	}
+	if !strings.Contains(last, "…") {
+		t.Errorf("long title should be truncated: %q", last)
+	}
+	if !strings.Contains(m.formatSidebarContent(), "┃") {
+		t.Errorf("sidebar should show a scrollbar thumb")
+	}
+
+	// Moving back up inside the window does not scroll
+	offset := m.sidebarOffset
+	m.cursor = 58
+	m.scrollSidebar()
+	if m.sidebarOffset != offset {
+		t.Errorf("offset changed from %d to %d", offset, m.sidebarOffset)
+	}
+
+	// A sidebar narrower than its padding and scrollbar still renders
+	for _, sidebarWidth := range []int{0, 1, 2, 4} {
+		m.config.Layout.SidebarWidth = sidebarWidth
+		if content := m.formatSidebarContent(); !strings.Contains(content, "┃") {
+			t.Errorf("sidebar_width %d: content = %q", sidebarWidth, content)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SortMode string
+
+const (
+	SortTitle    SortMode = "title"
+	SortFilename SortMode = "filename"
+	SortModified SortMode = "modified"
+	SortCreated  SortMode = "created"
+	SortManual   SortMode = "manual"
+)
+
+// sortModes is the order the sort key cycles through.
+var sortModes = []SortMode{SortFilename, SortTitle, SortModified, SortCreated, SortManual}
+
+type SortOrder struct {
+	Mode         SortMode `yaml:"mode"`
+	Descending   bool     `yaml:"descending"`
+	FoldersFirst bool     `yaml:"folders_first"`
+	Manual       []string `yaml:"manual,omitempty"` // entry names in manual order
+}
+
+type SortConfig struct {
+	Default SortOrder            `yaml:"default"`
+	Folders map[string]SortOrder `yaml:"folders,omitempty"` // keyed by folder path in the notes dir
+}
+
+// SortOrderFor returns the sort order of dir, falling back to the default.
+func (c *Config) SortOrderFor(dir string) SortOrder {
+	if order, ok := c.Sort.Folders[filepath.Clean(dir)]; ok {
+		return order
+	}
+	return c.Sort.Default
+}
+
+func (c *Config) SetSortOrder(dir string, order SortOrder) {
+	if c.Sort.Folders == nil {
+		c.Sort.Folders = make(map[string]SortOrder)
+	}
+	c.Sort.Folders[filepath.Clean(dir)] = order
+}
+
+func nextSortMode(mode SortMode) SortMode {
+	for i, m := range sortModes {
+		if m == mode {
+			return sortModes[(i+1)%len(sortModes)]
+		}
+	}
+	return sortModes[0]
+}
+
+type sortItem struct {
+	note    Note
+	name    string
+	modTime time.Time
+	created time.Time
+}
+
+func sortItems(items []sortItem, order SortOrder) {
+	manual := make(map[string]int)
+	for i, name := range order.Manual {
+		manual[name] = i
+	}
+
+	// less reports whether a sorts before b under the mode, ignoring direction
+	less := func(a, b sortItem) bool {
+		switch order.Mode {
+		case SortTitle:
+			ta, tb := strings.ToLower(a.note.title), strings.ToLower(b.note.title)
+			if ta != tb {
+				return ta < tb
+			}
+		case SortModified:
+			if !a.modTime.Equal(b.modTime) {
+				return a.modTime.Before(b.modTime)
+			}
+		case SortCreated:
+			if !a.created.Equal(b.created) {
+				return a.created.Before(b.created)
+			}
+		case SortManual:
+			ia, aok := manual[a.name]
+			ib, bok := manual[b.name]
+			if aok != bok {
+				return aok
+			}
+			if ia != ib {
+				return ia < ib
+			}
+		}
+		return a.name < b.name
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if order.FoldersFirst && a.note.isDir != b.note.isDir {
+			return a.note.isDir
+		}
+		if order.Descending && order.Mode != SortManual {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// cycleSortMode switches the current folder to the next sort mode and saves it.
+func (m *Model) cycleSortMode() {
+	dir := m.getCurrentDirectory()
+	order := m.config.SortOrderFor(dir)
+	order.Mode = nextSortMode(order.Mode)
+	if order.Mode == SortManual && len(order.Manual) == 0 {
+		order.Manual = m.siblingNames(dir)
+	}
+	m.setSortOrder(dir, order)
+}
+
+func (m *Model) toggleSortDirection() {
+	dir := m.getCurrentDirectory()
+	order := m.config.SortOrderFor(dir)
+	order.Descending = !order.Descending
+	m.setSortOrder(dir, order)
+}
+
+// moveManual moves the selected entry up or down among its siblings when its
+// folder is sorted manually.
+func (m *Model) moveManual(delta int) {
+	if len(m.notes) == 0 {
+		return
+	}
+	path := m.notes[m.cursor].path
+	dir := filepath.Dir(path)
+	order := m.config.SortOrderFor(dir)
+	if order.Mode != SortManual {
+		return
+	}
+
+	names := m.siblingNames(dir)
+	name := filepath.Base(path)
+	for i, n := range names {
+		if n == name {
+			j := i + delta
+			if j < 0 || j >= len(names) {
+				return
+			}
+			names[i], names[j] = names[j], names[i]
+			break
+		}
+	}
+	order.Manual = names
+	m.setSortOrder(dir, order)
+}
+
+func (m *Model) setSortOrder(dir string, order SortOrder) {
+	var selected string
+	if len(m.notes) > 0 {
+		selected = m.notes[m.cursor].path
+	}
+	m.config.SetSortOrder(dir, order)
+	m.saveConfig()
+	m.updateNotes()
+	if selected != "" {
+		m.revealNote(selected)
+	}
+}
+
+// siblingNames lists the entries of dir in their current display order.
+func (m Model) siblingNames(dir string) []string {
+	var names []string
+	for _, note := range m.notes {
+		if filepath.Dir(note.path) == filepath.Clean(dir) {
+			names = append(names, filepath.Base(note.path))
+		}
+	}
+	return names
+}
+
+func (o SortOrder) String() string {
+	arrow := "↑"
+	if o.Descending && o.Mode != SortManual {
+		arrow = "↓"
+	}
+	return "sorted by " + string(o.Mode) + " " + arrow
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortItems(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	items := []sortItem{
+		{note: Note{title: "Zebra"}, name: "a.md", modTime: day(3), created: day(1)},
+		{note: Note{title: "folder", isDir: true}, name: "folder", modTime: day(1), created: day(1)},
+		{note: Note{title: "apple"}, name: "c.md", modTime: day(1), created: day(3)},
+		{note: Note{title: "Mango"}, name: "b.md", modTime: day(2), created: day(2)},
+	}
+
+	tests := []struct {
+		name     string
+		order    SortOrder
+		expected []string
+	}{
+		{name: "filename", order: SortOrder{Mode: SortFilename}, expected: []string{"a.md", "b.md", "c.md", "folder"}},
+		{name: "folders first", order: SortOrder{Mode: SortFilename, FoldersFirst: true}, expected: []string{"folder", "a.md", "b.md", "c.md"}},
+		{name: "title is case insensitive", order: SortOrder{Mode: SortTitle, FoldersFirst: true}, expected: []string{"folder", "c.md", "b.md", "a.md"}},
+		{name: "modified descending", order: SortOrder{Mode: SortModified, Descending: true}, expected: []string{"a.md", "b.md", "folder", "c.md"}},
+		{name: "created", order: SortOrder{Mode: SortCreated}, expected: []string{"a.md", "folder", "b.md", "c.md"}},
+		{name: "manual with unlisted entries last", order: SortOrder{Mode: SortManual, Descending: true, Manual: []string{"c.md", "a.md"}}, expected: []string{"c.md", "a.md", "b.md", "folder"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]sortItem(nil), items...)
+			sortItems(sorted, tt.order)
+			var got []string
+			for _, item := range sorted {
+				got = append(got, item.name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("sortItems() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortOrderFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetSortOrder("projects/", SortOrder{Mode: SortTitle})
+	if got := cfg.SortOrderFor("projects").Mode; got != SortTitle {
+		t.Errorf("SortOrderFor(projects) = %v, want %v", got, SortTitle)
+	}
+	if got := cfg.SortOrderFor("."); got.Mode != SortFilename || !got.FoldersFirst {
+		t.Errorf("SortOrderFor(.) = %+v, want the default order", got)
+	}
+}
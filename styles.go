@@ -6,6 +6,8 @@ import (
 
 type Styles struct {
 	highlight lipgloss.Color
+	errorText lipgloss.Color
+	border    lipgloss.Border
 	sidebar   lipgloss.Style
 	viewport  lipgloss.Style
 	title     lipgloss.Style
@@ -13,25 +15,28 @@ type Styles struct {
 	welcome   lipgloss.Style
 	doc       lipgloss.Style
 	header    lipgloss.Style
+	muted     lipgloss.Style
 }
 
-func NewStyles(cfg *Config) Styles {
-	highlight := lipgloss.Color("#9D8CFF")
+func NewStyles(cfg *Config, theme Theme) Styles {
+	highlight := lipgloss.Color(theme.Highlight)
 	paddingH, paddingV := cfg.GetPadding()
 	dims := cfg.DefaultDimensions()
 
 	return Styles{
 		highlight: highlight,
+		errorText: lipgloss.Color(theme.Error),
+		border:    theme.border(),
 		sidebar: lipgloss.NewStyle().
 			Padding(paddingV, paddingH),
 		viewport: lipgloss.NewStyle(),
 		title: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFF7DB")).
+			Foreground(lipgloss.Color(theme.TitleText)).
 			Background(highlight).
 			Bold(true).
 			Padding(0, paddingH),
 		statusBar: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
+			Foreground(lipgloss.Color(theme.Muted)).
 			Height(dims.Heights.Status).
 			Padding(0, paddingH),
 		doc: lipgloss.NewStyle().
@@ -41,7 +46,9 @@ func NewStyles(cfg *Config) Styles {
 			Height(dims.Heights.Header).
 			Padding(0, paddingH).
 			Align(lipgloss.Center).
-			Foreground(lipgloss.Color("#FFFFFF")),
+			Foreground(lipgloss.Color(theme.HeaderText)),
+		muted: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(theme.Muted)),
 	}
 }
 
@@ -65,7 +72,7 @@ func (s Styles) RenderSidebar(width, height int, headerGap int) func(content str
 		return s.sidebar.
 			Height(height).
 			Width(width).
-			Border(lipgloss.RoundedBorder()).
+			Border(s.border).
 			BorderForeground(s.highlight).
 			MarginTop(headerGap).
 			Render(content)
@@ -75,7 +82,7 @@ func (s Styles) RenderSidebar(width, height int, headerGap int) func(content str
 func (s Styles) RenderContent(width, height int, headerGap int) func(content string) string {
 	return func(content string) string {
 		return lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
+			Border(s.border).
 			BorderForeground(s.highlight).
 			Width(width).
 			Height(height).
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// switcherHeight is how many candidates the quick-open overlay lists.
+const switcherHeight = 10
+
+type SwitchResult struct {
+	Path, Title string
+	Score       int
+}
+
+// fuzzyScore reports whether every rune of pattern appears in text in order
+// and scores the best such alignment. Matches at word starts and runs of
+// consecutive matches score higher, so "mn" prefers "Meeting Notes" over
+// "mountain".
+func fuzzyScore(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+	if len(p) > len(t) {
+		return 0, false
+	}
+
+	const none = -1
+	// prev[j] is the best score with the previous pattern rune matched at t[j]
+	prev := make([]int, len(t))
+	for j := range t {
+		prev[j] = none
+		if t[j] == p[0] {
+			prev[j] = runeBonus(t, j)
+		}
+	}
+
+	for i := 1; i < len(p); i++ {
+		cur := make([]int, len(t))
+		best := none // best prev[k] for k < j-1
+		for j := range t {
+			cur[j] = none
+			if j >= 2 && prev[j-2] > best {
+				best = prev[j-2]
+			}
+			if t[j] != p[i] || j == 0 {
+				continue
+			}
+			candidate := best
+			if prev[j-1] != none && prev[j-1]+4 > candidate {
+				candidate = prev[j-1] + 4
+			}
+			if candidate != none {
+				cur[j] = candidate + runeBonus(t, j)
+			}
+		}
+		prev = cur
+	}
+
+	score := none
+	for _, s := range prev {
+		if s > score {
+			score = s
+		}
+	}
+	if score == none {
+		return 0, false
+	}
+	// Prefer shorter candidates when scores tie
+	return score*100 - len(t), true
+}
+
+func runeBonus(t []rune, j int) int {
+	if j == 0 || !unicode.IsLetter(t[j-1]) && !unicode.IsDigit(t[j-1]) {
+		return 9
+	}
+	return 1
+}
+
+func (idx *Index) Switch(query string) []SwitchResult {
+	var results []SwitchResult
+	for path, entry := range idx.Entries {
+		titleScore, titleOK := fuzzyScore(query, entry.Title)
+		pathScore, pathOK := fuzzyScore(query, path)
+		if !titleOK && !pathOK {
+			continue
+		}
+		score := pathScore
+		if titleOK && titleScore > score {
+			score = titleScore
+		}
+		results = append(results, SwitchResult{Path: path, Title: entry.Title, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+func (m *Model) runSwitcher() {
+	m.switcherResults = m.index.Switch(m.switcherInput.Value())
+	m.switcherCursor = 0
+}
+
+func (m Model) updateSwitcher(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.inInput(panelPicker, msg) {
+	case actionClear:
+		m.switching = false
+		m.switcherInput.Blur()
+		return m, nil
+	case actionOpen:
+		if m.switcherCursor < len(m.switcherResults) {
+			if m.revealNote(m.switcherResults[m.switcherCursor].Path) {
+				m.updatePreview()
+			}
+		}
+		m.switching = false
+		m.switcherInput.Blur()
+		return m, nil
+	case actionUp, actionPrevResult:
+		if m.switcherCursor > 0 {
+			m.switcherCursor--
+		}
+		return m, nil
+	case actionDown, actionNextResult:
+		if m.switcherCursor < len(m.switcherResults)-1 {
+			m.switcherCursor++
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	query := m.switcherInput.Value()
+	m.switcherInput, cmd = m.switcherInput.Update(msg)
+	if m.switcherInput.Value() != query {
+		m.runSwitcher()
+	}
+	return m, cmd
+}
+
+func (m Model) renderSwitcher(width, height int) string {
+	boxWidth := width / 2
+	if boxWidth < 40 {
+		boxWidth = width - 4
+	}
+
+	lines := []string{m.switcherInput.View(), ""}
+	start := 0
+	if m.switcherCursor >= switcherHeight {
+		start = m.switcherCursor - switcherHeight + 1
+	}
+	for i := start; i < len(m.switcherResults) && i < start+switcherHeight; i++ {
+		result := m.switcherResults[i]
+		title := ansi.Truncate(result.Title, boxWidth-6, "…")
+		path := m.styles.muted.Render(ansi.Truncate(result.Path, boxWidth-6, "…"))
+		style := lipgloss.NewStyle()
+		if i == m.switcherCursor {
+			style = style.Foreground(m.styles.highlight).Bold(true)
+		}
+		lines = append(lines, style.Render(title)+"\n  "+path)
+	}
+	if len(m.switcherResults) == 0 {
+		lines = append(lines, m.styles.muted.Render("No matching notes"))
+	}
+
+	box := m.styles.doc.
+		Border(m.styles.border).
+		BorderForeground(m.styles.highlight).
+		Padding(0, 1).
+		Width(boxWidth).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Top, box)
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		match   bool
+	}{
+		{name: "subsequence matches", pattern: "mtn", text: "Meeting Notes", match: true},
+		{name: "case insensitive", pattern: "MN", text: "meeting notes", match: true},
+		{name: "order matters", pattern: "nm", text: "mn", match: false},
+		{name: "missing rune", pattern: "xyz", text: "Meeting Notes", match: false},
+		{name: "empty pattern matches everything", pattern: "", text: "anything", match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := fuzzyScore(tt.pattern, tt.text); got != tt.match {
+				t.Errorf("fuzzyScore(%q, %q) match = %v, want %v", tt.pattern, tt.text, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestFuzzyScorePrefersWordStarts(t *testing.T) {
+	wordStarts, _ := fuzzyScore("mn", "meeting notes")
+	scattered, _ := fuzzyScore("mn", "mountain")
+	if wordStarts <= scattered {
+		t.Errorf("word start score %d should beat scattered score %d", wordStarts, scattered)
+	}
+}
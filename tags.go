@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// extractTags collects tags from the frontmatter "tags" field and from inline
+// #hashtags outside code. Tags are lowercased, deduplicated and sorted.
+func extractTags(content string) []string {
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.ToLower(strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "#"), "/"))
+		if tag != "" {
+			seen[tag] = true
+		}
+	}
+
+	fm, body := parseFrontmatter(content)
+	for _, tag := range fm.Tags {
+		add(tag)
+	}
+
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, tag := range inlineTags(line) {
+			add(tag)
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// inlineTags finds #tag tokens in a line, skipping `inline code`, headings
+// and anchors such as [x](#section).
+func inlineTags(line string) []string {
+	var tags []string
+	runes := []rune(line)
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '`' {
+			inCode = !inCode
+			continue
+		}
+		if inCode || runes[i] != '#' {
+			continue
+		}
+		// A tag starts the line or follows whitespace
+		if i > 0 && !unicode.IsSpace(runes[i-1]) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isTagRune(runes[j]) {
+			j++
+		}
+		if j > i+1 && unicode.IsLetter(runes[i+1]) {
+			tags = append(tags, string(runes[i+1:j]))
+		}
+		i = j - 1
+	}
+	return tags
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
+
+// tagRow is one line of the tag sidebar: either a tag or a note under it.
+type tagRow struct {
+	tag      string // full tag path, e.g. "project/alpha"
+	depth    int
+	count    int
+	notePath string // set for note rows
+	title    string
+}
+
+// TagCounts maps every tag, including parents of nested tags, to the notes
+// carrying it. A note tagged #project/alpha also counts towards #project.
+func (idx *Index) TagCounts() map[string]map[string]bool {
+	tags := make(map[string]map[string]bool)
+	for path, entry := range idx.Entries {
+		for _, tag := range entry.Tags {
+			parts := strings.Split(tag, "/")
+			for i := range parts {
+				parent := strings.Join(parts[:i+1], "/")
+				if tags[parent] == nil {
+					tags[parent] = make(map[string]bool)
+				}
+				tags[parent][path] = true
+			}
+		}
+	}
+	return tags
+}
+
+// tagRows flattens the tag tree into rows, descending into expanded tags.
+func (idx *Index) tagRows(expanded map[string]bool) []tagRow {
+	counts := idx.TagCounts()
+	children := make(map[string][]string)
+	for tag := range counts {
+		parent := ""
+		if i := strings.LastIndex(tag, "/"); i >= 0 {
+			parent = tag[:i]
+		}
+		children[parent] = append(children[parent], tag)
+	}
+
+	var rows []tagRow
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		tags := children[parent]
+		sort.Strings(tags)
+		for _, tag := range tags {
+			rows = append(rows, tagRow{tag: tag, depth: depth, count: len(counts[tag])})
+			if !expanded[tag] {
+				continue
+			}
+			walk(tag, depth+1)
+
+			var paths []string
+			for path := range counts[tag] {
+				if hasTag(idx.Entries[path].Tags, tag) {
+					paths = append(paths, path)
+				}
+			}
+			sort.Strings(paths)
+			for _, path := range paths {
+				rows = append(rows, tagRow{tag: tag, depth: depth + 1, notePath: path, title: idx.Entries[path].Title})
+			}
+		}
+	}
+	walk("", 0)
+	return rows
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Model) loadTagRows() {
+	m.tagRows = m.index.tagRows(m.tagExpanded)
+	if m.tagCursor >= len(m.tagRows) {
+		m.tagCursor = len(m.tagRows) - 1
+	}
+	if m.tagCursor < 0 {
+		m.tagCursor = 0
+	}
+}
+
+func (m Model) updateTagView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.tagRows) == 0 {
+		if action := m.keys[msg.String()]; action == actionTags || action == actionClear {
+			m.tagView = false
+		}
+		return m, nil
+	}
+
+	row := m.tagRows[m.tagCursor]
+	switch m.keys[msg.String()] {
+	case actionTags, actionClear:
+		m.tagView = false
+	case actionUp:
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case actionDown:
+		if m.tagCursor < len(m.tagRows)-1 {
+			m.tagCursor++
+		}
+	case actionExpand:
+		if row.notePath == "" {
+			m.tagExpanded[row.tag] = true
+			m.loadTagRows()
+		}
+	case actionCollapse:
+		if row.notePath != "" || !m.tagExpanded[row.tag] {
+			// Jump to and collapse the parent tag row
+			for i := m.tagCursor - 1; i >= 0; i-- {
+				if m.tagRows[i].notePath == "" && m.tagRows[i].depth < row.depth {
+					m.tagCursor = i
+					break
+				}
+			}
+			row = m.tagRows[m.tagCursor]
+		}
+		m.tagExpanded[row.tag] = false
+		m.loadTagRows()
+	case actionOpen:
+		if row.notePath == "" {
+			m.tagExpanded[row.tag] = !m.tagExpanded[row.tag]
+			m.loadTagRows()
+		} else {
+			m.openNote(row.notePath)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) formatTagSidebar(width, height int) string {
+	if len(m.tagRows) == 0 {
+		return m.styles.muted.Render("No tags found")
+	}
+
+	start := 0
+	if m.tagCursor >= height {
+		start = m.tagCursor - height + 1
+	}
+	var lines []string
+	for i := start; i < len(m.tagRows) && len(lines) < height; i++ {
+		row := m.tagRows[i]
+		style := lipgloss.NewStyle()
+		if i == m.tagCursor {
+			style = style.Foreground(m.styles.highlight)
+		}
+
+		indent := strings.Repeat("  ", row.depth)
+		var line string
+		if row.notePath != "" {
+			line = indent + "└─ " + row.title
+		} else {
+			icon := "▶ "
+			if m.tagExpanded[row.tag] {
+				icon = "▼ "
+			}
+			name := row.tag[strings.LastIndex(row.tag, "/")+1:]
+			line = fmt.Sprintf("%s%s#%s (%d)", indent, icon, name, row.count)
+		}
+		lines = append(lines, style.Render(ansi.Truncate(line, width, "…")))
+	}
+	return strings.Join(lines, "\n")
+}
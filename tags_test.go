@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "frontmatter list",
+			content:  "---\ntags: [Work, project/alpha]\n---\n# Title\n",
+			expected: []string{"project/alpha", "work"},
+		},
+		{
+			name:     "frontmatter string",
+			content:  "---\ntags: work, home\n---\n",
+			expected: []string{"home", "work"},
+		},
+		{
+			name:     "inline hashtags",
+			content:  "# Heading\nSome #idea and #project/beta, not a#tag or #123\n",
+			expected: []string{"idea", "project/beta"},
+		},
+		{
+			name:     "code is ignored",
+			content:  "`#notatag` #real\n```\n#fenced\n```\n",
+			expected: []string{"real"},
+		},
+		{
+			name:     "anchors are ignored",
+			content:  "[jump](#section)\n",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractTags(tt.content); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("extractTags() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTagRows(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		"a.md": "# A\n#project/alpha\n",
+		"b.md": "# B\n#project #home\n",
+	})
+	idx := newIndex(filepath.Join(t.TempDir(), "index.gob"), root)
+	idx.Refresh(root, func(string) bool { return false })
+
+	rows := idx.tagRows(map[string]bool{"project": true})
+	var got []string
+	for _, row := range rows {
+		if row.notePath != "" {
+			got = append(got, "note:"+row.title)
+		} else {
+			got = append(got, row.tag)
+		}
+	}
+	want := []string{"home", "project", "project/alpha", "note:B"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tagRows() = %v, want %v", got, want)
+	}
+	if rows[1].count != 2 {
+		t.Errorf("project count = %d, want 2", rows[1].count)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Theme holds every color and border used by Styles plus the glamour style
+// for the preview. Themes are read from ConfigDir/themes/<name>.yaml; keys
+// missing from a file keep the default theme's values.
+type Theme struct {
+	Name       string `yaml:"-"`
+	Highlight  string `yaml:"highlight"`
+	TitleText  string `yaml:"title_text"`
+	HeaderText string `yaml:"header_text"`
+	Muted      string `yaml:"muted"`
+	Error      string `yaml:"error"`
+	Border     string `yaml:"border"` // rounded, normal, thick, double or hidden
+	// A glamour standard style (auto, dark, light, dracula, pink, ascii,
+	// notty) or a JSON style file, relative to the themes directory
+	GlamourStyle string `yaml:"glamour_style"`
+}
+
+var defaultTheme = Theme{
+	Name:         "default",
+	Highlight:    "#9D8CFF",
+	TitleText:    "#FFF7DB",
+	HeaderText:   "#FFFFFF",
+	Muted:        "#626262",
+	Error:        "#FF5F87",
+	Border:       "rounded",
+	GlamourStyle: "auto",
+}
+
+var builtinThemes = []Theme{
+	defaultTheme,
+	{
+		Name:         "light",
+		Highlight:    "#7D56F4",
+		TitleText:    "#FFFFFF",
+		HeaderText:   "#1A1A1A",
+		Muted:        "#8A8A8A",
+		Error:        "#D7005F",
+		Border:       "rounded",
+		GlamourStyle: "light",
+	},
+	{
+		Name:         "dracula",
+		Highlight:    "#BD93F9",
+		TitleText:    "#282A36",
+		HeaderText:   "#F8F8F2",
+		Muted:        "#6272A4",
+		Error:        "#FF5555",
+		Border:       "normal",
+		GlamourStyle: "dracula",
+	},
+}
+
+func themesDir(configDir string) string {
+	return filepath.Join(configDir, "themes")
+}
+
+// LoadThemes returns the built-in themes plus every theme file in the themes
+// directory, sorted by name. A file named after a built-in theme replaces it.
+// Files that cannot be read or parsed are skipped and reported in errs.
+func LoadThemes(configDir string) (list []Theme, errs []error) {
+	themes := make(map[string]Theme)
+	for _, theme := range builtinThemes {
+		themes[theme.Name] = theme
+	}
+
+	dir := themesDir(configDir)
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if f.IsDir() || (!strings.HasSuffix(f.Name(), ".yaml") && !strings.HasSuffix(f.Name(), ".yml")) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme %s: %v", f.Name(), err))
+			continue
+		}
+		theme := defaultTheme
+		if err := yaml.Unmarshal(data, &theme); err != nil {
+			errs = append(errs, fmt.Errorf("theme %s: %v", f.Name(), err))
+			continue
+		}
+		theme.Name = strings.TrimSuffix(strings.TrimSuffix(f.Name(), ".yaml"), ".yml")
+		if theme.GlamourStyle != "" && strings.HasSuffix(theme.GlamourStyle, ".json") && !filepath.IsAbs(theme.GlamourStyle) {
+			theme.GlamourStyle = filepath.Join(dir, theme.GlamourStyle)
+		}
+		themes[theme.Name] = theme
+	}
+
+	list = make([]Theme, 0, len(themes))
+	for _, theme := range themes {
+		list = append(list, theme)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, errs
+}
+
+// findTheme looks up a theme by name, falling back to the default theme.
+func findTheme(themes []Theme, name string) Theme {
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme
+		}
+	}
+	return defaultTheme
+}
+
+// ActiveThemeName picks the configured theme for the terminal background.
+func (c *Config) ActiveThemeName() string {
+	if lipgloss.HasDarkBackground() {
+		return c.Theme.Dark
+	}
+	return c.Theme.Light
+}
+
+// SetActiveThemeName stores name as the theme for the terminal background.
+func (c *Config) SetActiveThemeName(name string) {
+	if lipgloss.HasDarkBackground() {
+		c.Theme.Dark = name
+	} else {
+		c.Theme.Light = name
+	}
+}
+
+func (t Theme) border() lipgloss.Border {
+	switch t.Border {
+	case "normal":
+		return lipgloss.NormalBorder()
+	case "thick":
+		return lipgloss.ThickBorder()
+	case "double":
+		return lipgloss.DoubleBorder()
+	case "hidden":
+		return lipgloss.HiddenBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+func newMarkdownRenderer(theme Theme, width int) (*glamour.TermRenderer, error) {
+	style := theme.GlamourStyle
+	if style == "" {
+		style = "auto"
+	}
+	return glamour.NewTermRenderer(
+		glamour.WithStylePath(style),
+		glamour.WithWordWrap(width),
+	)
+}
+
+// cycleTheme switches to the next available theme, re-rendering the preview
+// and remembering the choice for the current terminal background.
+func (m *Model) cycleTheme() {
+	themes, errs := LoadThemes(m.config.ConfigDir)
+	for _, err := range errs {
+		m.notifyError("Could not load %v", err)
+	}
+	next := themes[0]
+	for i, theme := range themes {
+		if theme.Name == m.theme.Name {
+			next = themes[(i+1)%len(themes)]
+			break
+		}
+	}
+
+	m.theme = next
+	m.styles = NewStyles(m.config, next)
+	m.viewport.Style = m.styles.viewport
+	if renderer, err := newMarkdownRenderer(next, m.viewport.Width-4); err != nil {
+		m.notifyError("Could not load glamour style %s: %v", next.GlamourStyle, err)
+	} else {
+		m.mdRenderer = renderer
+		if m.previews != nil {
+			m.previews.clear()
+		}
+	}
+	m.config.SetActiveThemeName(next.Name)
+	m.saveConfig()
+	m.notify("Theme: %s", next.Name)
+	if m.currentNotePath() != "" {
+		m.renderPreview()
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadThemes(t *testing.T) {
+	configDir := t.TempDir()
+	dir := themesDir(configDir)
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "ocean.yaml"), []byte("highlight: \"#00AAFF\"\nborder: thick\nglamour_style: ocean.json\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("highlight: [\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	themes, errs := LoadThemes(configDir)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.yaml") {
+		t.Errorf("LoadThemes() errors = %v, want broken.yaml reported", errs)
+	}
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+	if len(names) != 4 || names[0] != "default" || names[3] != "ocean" {
+		t.Fatalf("LoadThemes() names = %v, want built-ins plus ocean", names)
+	}
+
+	ocean := findTheme(themes, "ocean")
+	if ocean.Highlight != "#00AAFF" || ocean.Border != "thick" {
+		t.Errorf("ocean theme = %+v", ocean)
+	}
+	if ocean.Muted != defaultTheme.Muted {
+		t.Errorf("missing keys should inherit the default theme, got muted %q", ocean.Muted)
+	}
+	if ocean.GlamourStyle != filepath.Join(dir, "ocean.json") {
+		t.Errorf("glamour style = %q, want it resolved in the themes dir", ocean.GlamourStyle)
+	}
+
+	if got := findTheme(themes, "missing"); got.Name != "default" {
+		t.Errorf("findTheme(missing) = %v, want default", got.Name)
+	}
+}
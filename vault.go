@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// vault is the notes directory opened outside the TUI, with its index
+// refreshed. Opening it changes into the notes dir like the TUI does.
+type vault struct {
+	cfg   *Config
+	index *Index
+	wd    string // working directory before opening, for resolving arguments
+}
+
+func openVault() (*vault, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(cfg.NotesDir); err != nil {
+		return nil, fmt.Errorf("failed to open notes directory: %v", err)
+	}
+	index := OpenIndex(filepath.Join(cfg.ConfigDir, "index.gob"), cfg.NotesDir)
+	index.Refresh(".", cfg.isArchiveDir)
+	index.Save()
+	return &vault{cfg: cfg, index: index, wd: wd}, nil
+}
+
+// resolve finds the note or folder named by a command line argument: a path
+// relative to the original working directory or the notes dir, or anything a
+// [[wikilink]] could point at.
+func (v *vault) resolve(arg string) (string, error) {
+	if !filepath.IsAbs(arg) {
+		if _, err := os.Stat(filepath.Join(v.wd, arg)); err == nil {
+			arg = filepath.Join(v.wd, arg)
+		}
+	}
+	if path, err := vaultPath(v.cfg, arg); err == nil {
+		return path, nil
+	}
+	if path, ok := v.index.ResolveLink(arg, ""); ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s is not a note or folder in %s", arg, v.cfg.NotesDir)
+}
+
+// insidePath turns a path that may not exist yet, relative to the notes dir or
+// absolute, into one relative to the notes dir. Paths that lead out of the
+// notes dir are rejected.
+func (v *vault) insidePath(path string) (string, error) {
+	rel := filepath.Clean(path)
+	if filepath.IsAbs(rel) {
+		if r, err := filepath.Rel(v.cfg.NotesDir, rel); err == nil {
+			rel = r
+		}
+	}
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s", path, v.cfg.NotesDir)
+	}
+	return rel, nil
+}
+
+func (c *Config) isArchiveDir(path string) bool {
+	return path == c.ArchiveDir || filepath.Base(path) == "archive"
+}
+
+// createNote writes a new timestamped note in dir, followed by body if given,
+// and returns its path.
+func createNote(dir, title, body string) (string, error) {
+	if title == "" {
+		title = "New Note"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	name := "note-" + now.Format("2006-01-02-150405")
+	path := filepath.Join(dir, name+".md")
+	for counter := 2; ; counter++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", name, counter))
+	}
+
+	content := fmt.Sprintf("# %s\n\nCreated: %s\n", title, now.Format("2006-01-02 15:04:05"))
+	if body = strings.TrimSpace(body); body != "" {
+		content += "\n" + body + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// appendBullet adds text to the end of the note at path as a bullet stamped
+// with the current time. Further lines of text are indented under it.
+func appendBullet(path, text string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	bullet := "- " + time.Now().Format("2006-01-02 15:04") + " " + lines[0] + "\n"
+	for _, line := range lines[1:] {
+		bullet += "  " + line + "\n"
+	}
+
+	text = string(content)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return writeFileAtomic(path, []byte(text+bullet))
+}
+
+// createFolder makes a folder called "New Folder" in dir, numbering it if the
+// name is taken, and returns its path.
+func createFolder(dir string) (string, error) {
+	baseName := "New Folder"
+	path := filepath.Join(dir, baseName)
+	for counter := 1; ; counter++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s %d", baseName, counter))
+	}
+	return path, os.MkdirAll(path, 0755)
+}
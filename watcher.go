@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Values of the watch option.
+const (
+	watchAuto = "auto" // inotify and friends, polling where they fail
+	watchPoll = "poll"
+	watchOff  = "off"
+)
+
+// watchDebounce collects the burst of events from a git pull or a sync into
+// one refresh.
+const watchDebounce = 200 * time.Millisecond
+
+// pollInterval is how often the polling watcher scans the notes directory.
+var pollInterval = 2 * time.Second
+
+// notesChangedMsg reports files changed outside the app, relative to the
+// notes directory.
+type notesChangedMsg struct {
+	paths []string
+	err   error
+}
+
+// noteWatcher watches the notes directory tree and sends notesChangedMsg on
+// its channel.
+type noteWatcher struct {
+	root    string
+	skip    func(path string) bool
+	changes chan notesChangedMsg
+	done    chan struct{}
+	fs      *fsnotify.Watcher
+}
+
+// watchNotes starts watching root in the given mode. Hidden files and paths
+// for which skip returns true are ignored. It returns nil when watching is
+// off.
+func watchNotes(root, mode string, skip func(path string) bool) (*noteWatcher, error) {
+	if mode == watchOff {
+		return nil, nil
+	}
+	w := &noteWatcher{
+		root:    root,
+		skip:    skip,
+		changes: make(chan notesChangedMsg),
+		done:    make(chan struct{}),
+	}
+
+	var err error
+	if mode == watchAuto {
+		if w.fs, err = fsnotify.NewWatcher(); err == nil {
+			if err = w.addTree(root, nil); err == nil {
+				go w.watch()
+				return w, nil
+			}
+			w.fs.Close()
+			w.fs = nil
+		}
+	}
+	go w.poll()
+	if err != nil {
+		err = fmt.Errorf("falling back to polling: %v", err)
+	}
+	return w, err
+}
+
+func (w *noteWatcher) Close() {
+	if w == nil {
+		return
+	}
+	close(w.done)
+	if w.fs != nil {
+		w.fs.Close()
+	}
+}
+
+// wait returns a command that delivers the next change. The model issues it
+// again after every change it receives.
+func (w *noteWatcher) wait() tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case msg := <-w.changes:
+			return msg
+		case <-w.done:
+			return nil
+		}
+	}
+}
+
+func (w *noteWatcher) ignored(path string) bool {
+	path = filepath.Clean(path)
+	if path != "." && strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	return w.skip != nil && w.skip(path)
+}
+
+// addTree watches dir and every folder below it, as fsnotify is not
+// recursive, and calls found for everything in them.
+func (w *noteWatcher) addTree(dir string, found func(path string)) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if w.ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if found != nil {
+			found(path)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return w.fs.Add(path)
+	})
+}
+
+func (w *noteWatcher) send(msg notesChangedMsg) bool {
+	select {
+	case w.changes <- msg:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *noteWatcher) watch() {
+	pending := make(map[string]bool)
+	var flush <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			path := filepath.Clean(event.Name)
+			if w.ignored(path) {
+				continue
+			}
+			pending[path] = true
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					// Files can land in a new folder before it is watched
+					err := w.addTree(path, func(path string) { pending[path] = true })
+					if err != nil && !w.send(notesChangedMsg{err: fmt.Errorf("watching %s: %v", path, err)}) {
+						return
+					}
+				}
+			}
+			if flush == nil {
+				flush = time.After(watchDebounce)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			if !w.send(notesChangedMsg{err: err}) {
+				return
+			}
+		case <-flush:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			flush = nil
+			if !w.send(notesChangedMsg{paths: paths}) {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// fileState is what the polling watcher compares between scans.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (w *noteWatcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+	filepath.WalkDir(w.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == w.root {
+			return nil
+		}
+		if w.ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = fileState{info.ModTime(), info.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+func (w *noteWatcher) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	last := w.scan()
+	for {
+		select {
+		case <-ticker.C:
+			current := w.scan()
+			var paths []string
+			for path, state := range current {
+				if old, ok := last[path]; !ok || old != state {
+					paths = append(paths, path)
+				}
+			}
+			for path := range last {
+				if _, ok := current[path]; !ok {
+					paths = append(paths, path)
+				}
+			}
+			last = current
+			if len(paths) == 0 {
+				continue
+			}
+			sort.Strings(paths)
+			if !w.send(notesChangedMsg{paths: paths}) {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// reindex brings the index up to date with the changed paths. A path that is
+// gone takes everything indexed below it along; files in new folders are
+// reported on their own.
+func (m *Model) reindex(paths []string) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			m.index.Remove(path)
+			prefix := path + string(filepath.Separator)
+			for indexed := range m.index.Entries {
+				if strings.HasPrefix(indexed, prefix) {
+					m.index.Remove(indexed)
+				}
+			}
+		case !info.IsDir() && strings.HasSuffix(path, ".md"):
+			m.index.Update(path, info)
+		}
+	}
+}
+
+// inTree reports whether path is listed in the tree, or would be: it is at
+// the top or in an expanded folder.
+func (m Model) inTree(path string) bool {
+	dir := filepath.Dir(path)
+	if dir == "." {
+		return true
+	}
+	for _, note := range m.notes {
+		if note.isDir && note.path == dir {
+			return note.expanded
+		}
+	}
+	return false
+}
+
+// notesChanged reloads what changed outside the app, keeping the selection,
+// the expanded folders and, when the open note changed, the preview's scroll
+// position. The tree is only reloaded when a change shows in it.
+func (m *Model) notesChanged(msg notesChangedMsg) {
+	if msg.err != nil {
+		m.notifyError("File watcher: %v", msg.err)
+		return
+	}
+
+	changed := make(map[string]bool)
+	for _, path := range msg.paths {
+		changed[path] = true
+	}
+	if m.editing && changed[m.editPath] {
+		m.notify("%s changed on disk; saving will overwrite it", m.editPath)
+	}
+
+	selected := ""
+	if m.cursor < len(m.notes) {
+		selected = m.notes[m.cursor].path
+	}
+	m.reindex(msg.paths)
+	for _, path := range msg.paths {
+		if m.inTree(path) {
+			m.updateNotes()
+			break
+		}
+	}
+
+	found := false
+	for i, note := range m.notes {
+		if note.path == selected {
+			m.cursor = i
+			found = true
+			break
+		}
+	}
+	if !found {
+		// The selected note went away: stay at the same place in the tree
+		if m.cursor >= len(m.notes) {
+			m.cursor = len(m.notes) - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		m.updatePreview()
+		return
+	}
+	if changed[selected] {
+		offset := m.previewOffset()
+		m.updatePreview()
+		m.scrollPreview(previewTarget{offset: offset})
+	}
+}
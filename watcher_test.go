@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+func TestWatchNotes(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 50 * time.Millisecond
+
+	for _, mode := range []string{watchAuto, watchPoll} {
+		t.Run(mode, func(t *testing.T) {
+			root := t.TempDir()
+			chdir(t, root)
+			writeNotes(t, root, map[string]string{"a.md": "# A\n", "archive/old.md": "# Old\n"})
+			skip := func(path string) bool { return path == "archive" }
+
+			w, err := watchNotes(".", mode, skip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+
+			// Give the polling watcher its first scan
+			time.Sleep(2 * pollInterval)
+			os.WriteFile(filepath.Join("archive", "old.md"), []byte("# Changed\n"), 0644)
+			os.Mkdir("sub", 0755)
+			os.WriteFile(filepath.Join("sub", "b.md"), []byte("# B\n"), 0644)
+			os.WriteFile(".hidden.md", []byte("x"), 0644)
+
+			seen := make(map[string]bool)
+			timeout := time.After(5 * time.Second)
+			for !seen[filepath.Join("sub", "b.md")] {
+				msgs := make(chan notesChangedMsg)
+				go func() {
+					if msg, ok := w.wait()().(notesChangedMsg); ok {
+						msgs <- msg
+					}
+				}()
+				select {
+				case msg := <-msgs:
+					if msg.err != nil {
+						t.Fatal(msg.err)
+					}
+					for _, path := range msg.paths {
+						seen[path] = true
+					}
+				case <-timeout:
+					t.Fatalf("no change reported for sub/b.md, saw %v", seen)
+				}
+			}
+			for path := range seen {
+				if strings.HasPrefix(path, "archive") || strings.HasPrefix(path, ".") {
+					t.Errorf("change to %s should be ignored", path)
+				}
+			}
+		})
+	}
+}
+
+func TestNotesChanged(t *testing.T) {
+	root := t.TempDir()
+	chdir(t, root)
+	writeNotes(t, root, map[string]string{
+		"b.md":        "# B\n",
+		"work/one.md": "# One\n",
+		"work/two.md": "# Two\n",
+	})
+	m := Model{
+		config:   DefaultConfig(),
+		index:    newIndex(filepath.Join(t.TempDir(), "index.gob"), root),
+		viewport: viewport.New(80, 3),
+	}
+	m.updateNotes()
+	m.revealNote(filepath.Join("work", "two.md"))
+	m.updatePreview()
+
+	// Another tool adds a note above the selection and edits the open one
+	writeNotes(t, root, map[string]string{
+		"a.md":        "# A\n",
+		"work/two.md": "# Two\n\nline 1\nline 2\nline 3\nline 4\n",
+	})
+	m.viewport.SetYOffset(0)
+	m.notesChanged(notesChangedMsg{paths: []string{"a.md", filepath.Join("work", "two.md")}})
+
+	if got := m.notes[m.cursor].path; got != filepath.Join("work", "two.md") {
+		t.Errorf("selection moved to %s", got)
+	}
+	if !strings.Contains(m.notes[m.cursor].content, "line 4") {
+		t.Errorf("preview was not reloaded: %q", m.notes[m.cursor].content)
+	}
+	var titles []string
+	for _, note := range m.notes {
+		titles = append(titles, note.title)
+	}
+	if strings.Join(titles, ",") != "work,One,Two,A,B" {
+		t.Errorf("tree = %v, want the work folder to stay expanded", titles)
+	}
+
+	// Deleting the selected note keeps the cursor in place
+	os.Remove(filepath.Join("work", "two.md"))
+	m.notesChanged(notesChangedMsg{paths: []string{filepath.Join("work", "two.md")}})
+	if m.cursor != 2 || m.notes[m.cursor].path != "a.md" {
+		t.Errorf("cursor = %d on %s after the selected note was removed", m.cursor, m.notes[m.cursor].path)
+	}
+	if m.index.Entries["a.md"] == nil || m.index.Entries[filepath.Join("work", "two.md")] != nil {
+		t.Errorf("index was not updated from the changed paths: %v", m.index.Entries)
+	}
+
+	// A folder moved away takes its notes out of the index
+	os.RemoveAll("work")
+	m.notesChanged(notesChangedMsg{paths: []string{"work"}})
+	if m.index.Entries[filepath.Join("work", "one.md")] != nil {
+		t.Errorf("notes of a removed folder are still indexed")
+	}
+	if len(m.notes) != 2 {
+		t.Errorf("tree has %d items after removing work, want 2", len(m.notes))
+	}
+}